@@ -0,0 +1,135 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/willabides/overexported/internal/overexported"
+)
+
+const apidiffDescription = `
+Analyze two checkouts of a module and compare their exported API. It reports
+exports that were used outside their package in the old checkout but are
+over-exported in the new one, which are candidates for deprecation and
+removal, and exports that are new in the new checkout. Identifiers are
+matched by package path and name.
+`
+
+type apidiffCmd struct {
+	Test     bool     `help:"Include test packages and executables in both analyses."`
+	JSON     bool     `help:"Output a JSON object."`
+	Old      string   `arg:"" type:"existingdir" help:"Directory of the old checkout."`
+	New      string   `arg:"" type:"existingdir" help:"Directory of the new checkout."`
+	Packages []string `arg:"" optional:"" help:"Package patterns to analyze in both checkouts. Defaults to ./..."`
+}
+
+// apiDiff is the output of the apidiff subcommand.
+type apiDiff struct {
+	// NewlyOverExported are used in the old checkout and over-exported in
+	// the new one.
+	NewlyOverExported []overexported.InventoryEntry `json:"newlyOverExported"`
+	// Added are exports of the new checkout that aren't in the old one.
+	Added []overexported.InventoryEntry `json:"added"`
+}
+
+func runAPIDiff(stdout io.Writer, args []string) error {
+	var c apidiffCmd
+	p, err := kong.New(&c,
+		kong.Name("overexported apidiff"),
+		kong.Description(strings.TrimSpace(apidiffDescription)),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = p.Parse(args)
+	if err != nil {
+		return err
+	}
+	return c.apidiff(stdout)
+}
+
+func (c *apidiffCmd) apidiff(stdout io.Writer) error {
+	patterns := c.Packages
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	oldInventory, err := c.inventory(c.Old, patterns)
+	if err != nil {
+		return err
+	}
+	newInventory, err := c.inventory(c.New, patterns)
+	if err != nil {
+		return err
+	}
+
+	diff := apiDiff{
+		NewlyOverExported: []overexported.InventoryEntry{},
+		Added:             []overexported.InventoryEntry{},
+	}
+	for key, entry := range newInventory {
+		old, ok := oldInventory[key]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, entry)
+		case old.Status == "used" && entry.Status == "over-exported":
+			diff.NewlyOverExported = append(diff.NewlyOverExported, entry)
+		}
+	}
+	for _, entries := range [][]overexported.InventoryEntry{diff.NewlyOverExported, diff.Added} {
+		slices.SortFunc(entries, func(a, b overexported.InventoryEntry) int {
+			return cmp.Or(cmp.Compare(a.PkgPath, b.PkgPath), cmp.Compare(a.Name, b.Name))
+		})
+	}
+
+	if c.JSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(diff)
+	}
+	return printAPIDiff(stdout, diff)
+}
+
+// inventory runs the analysis in dir and returns its inventory keyed by
+// "pkgpath.Name".
+func (c *apidiffCmd) inventory(dir string, patterns []string) (map[string]overexported.InventoryEntry, error) {
+	result, err := overexported.Run(patterns, &overexported.Options{
+		Test:                   c.Test,
+		ExternalTestAsExternal: true,
+		Dir:                    dir,
+		Inventory:              true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("apidiff %s: %w", dir, err)
+	}
+	inventory := make(map[string]overexported.InventoryEntry, len(result.Inventory))
+	for _, entry := range result.Inventory {
+		inventory[entry.PkgPath+"."+entry.Name] = entry
+	}
+	return inventory, nil
+}
+
+func printAPIDiff(stdout io.Writer, diff apiDiff) error {
+	var buf strings.Builder
+	if len(diff.NewlyOverExported) == 0 && len(diff.Added) == 0 {
+		buf.WriteString("No API changes found.\n")
+	}
+	if len(diff.NewlyOverExported) > 0 {
+		buf.WriteString("Used before, now over-exported:\n")
+		for _, entry := range diff.NewlyOverExported {
+			fmt.Fprintf(&buf, "  %s.%s (%s)\n", entry.PkgPath, entry.Name, entry.Kind)
+		}
+	}
+	if len(diff.Added) > 0 {
+		buf.WriteString("Added:\n")
+		for _, entry := range diff.Added {
+			fmt.Fprintf(&buf, "  %s.%s (%s) %s\n", entry.PkgPath, entry.Name, entry.Kind, entry.Status)
+		}
+	}
+	_, err := io.WriteString(stdout, buf.String())
+	return err
+}
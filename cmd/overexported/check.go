@@ -0,0 +1,55 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/willabides/overexported/internal/overexported"
+)
+
+// applyBaseline removes the exports in baseline from result, leaving only
+// regressions, and returns the baseline exports that are no longer reported.
+// Exports are matched by package, name and kind, so moving code around
+// doesn't count as a change.
+func applyBaseline(result *overexported.Result, baseline []overexported.Export) []overexported.Export {
+	inBaseline := make(map[mergeKey]bool, len(baseline))
+	for _, exp := range baseline {
+		inBaseline[mergeKey{pkgPath: exp.PkgPath, name: exp.Name, kind: exp.Kind}] = true
+	}
+	current := make(map[mergeKey]bool, len(result.Exports))
+	for _, exp := range result.Exports {
+		current[mergeKey{pkgPath: exp.PkgPath, name: exp.Name, kind: exp.Kind}] = true
+	}
+	result.Exports = slices.DeleteFunc(result.Exports, func(exp overexported.Export) bool {
+		return inBaseline[mergeKey{pkgPath: exp.PkgPath, name: exp.Name, kind: exp.Kind}]
+	})
+
+	var fixed []overexported.Export
+	for _, exp := range baseline {
+		key := mergeKey{pkgPath: exp.PkgPath, name: exp.Name, kind: exp.Kind}
+		if !current[key] {
+			fixed = append(fixed, exp)
+			current[key] = true
+		}
+	}
+	slices.SortFunc(fixed, func(a, b overexported.Export) int {
+		return cmp.Or(cmp.Compare(a.PkgPath, b.PkgPath), cmp.Compare(a.Name, b.Name))
+	})
+	return fixed
+}
+
+// fixedSection returns a text output section listing baseline exports that
+// are no longer reported, or an empty string if there are none.
+func fixedSection(fixed []overexported.Export) string {
+	if len(fixed) == 0 {
+		return ""
+	}
+	var buf strings.Builder
+	buf.WriteString("\nIn the baseline but no longer reported:\n")
+	for _, exp := range fixed {
+		fmt.Fprintf(&buf, "  %s.%s (%s)\n", exp.PkgPath, exp.Name, exp.Kind)
+	}
+	return buf.String()
+}
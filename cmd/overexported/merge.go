@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/willabides/overexported/internal/overexported"
+)
+
+const mergeDescription = `
+Combine saved --json or --json-envelope output from several runs of
+overexported, matching identifiers by package, name and kind. The result is
+written as JSON records.
+`
+
+type mergeCmd struct {
+	Intersect bool     `xor:"op" required:"" help:"Keep identifiers reported by every run. These are safe to unexport in all configurations."`
+	Union     bool     `xor:"op" required:"" help:"Keep identifiers reported by any run."`
+	Files     []string `arg:"" type:"existingfile" help:"JSON output files from --json or --json-envelope."`
+}
+
+// mergeKey identifies an export across runs.
+type mergeKey struct {
+	pkgPath, name, kind string
+}
+
+func runMerge(stdout io.Writer, args []string) error {
+	var c mergeCmd
+	p, err := kong.New(&c,
+		kong.Name("overexported merge"),
+		kong.Description(strings.TrimSpace(mergeDescription)),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = p.Parse(args)
+	if err != nil {
+		return err
+	}
+	return c.merge(stdout)
+}
+
+func (c *mergeCmd) merge(stdout io.Writer) error {
+	counts := make(map[mergeKey]int)
+	exports := make(map[mergeKey]overexported.Export)
+	for _, filename := range c.Files {
+		fileExports, err := readResultFile(filename)
+		if err != nil {
+			return err
+		}
+		seen := make(map[mergeKey]bool)
+		for _, exp := range fileExports {
+			key := mergeKey{pkgPath: exp.PkgPath, name: exp.Name, kind: exp.Kind}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			counts[key]++
+			if _, ok := exports[key]; !ok {
+				exports[key] = exp
+			}
+		}
+	}
+
+	merged := []overexported.Export{}
+	for key, exp := range exports {
+		if c.Intersect && counts[key] < len(c.Files) {
+			continue
+		}
+		merged = append(merged, exp)
+	}
+	slices.SortFunc(merged, func(a, b overexported.Export) int {
+		return cmp.Or(
+			cmp.Compare(a.PkgPath, b.PkgPath),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Kind, b.Kind),
+		)
+	})
+	return printResultJSON(stdout, &overexported.Result{Exports: merged})
+}
+
+// readResultFile reads exports from a file written with --json or
+// --json-envelope.
+func readResultFile(filename string) ([]overexported.Export, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var exports []overexported.Export
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var env jsonEnvelope
+		err = json.Unmarshal(data, &env)
+		exports = env.Exports
+	} else {
+		err = json.Unmarshal(data, &exports)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filename, err)
+	}
+	return exports, nil
+}
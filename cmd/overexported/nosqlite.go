@@ -0,0 +1,15 @@
+//go:build !sqlite
+
+package main
+
+import (
+	"errors"
+
+	"github.com/willabides/overexported/internal/overexported"
+)
+
+// writeSQLite fails in builds without the sqlite tag, which leave out the
+// SQLite driver.
+func writeSQLite(string, *overexported.Result, string) error {
+	return errors.New("--sqlite: this build has no SQLite support; build with -tags sqlite to enable it")
+}
@@ -0,0 +1,17 @@
+//go:build !sqlite
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_run_sqlite(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "findings.db")
+	_, err := runOverexported(t, "-C", "testdata/foo", "--sqlite", dbFile, "./...")
+	require.ErrorContains(t, err, "-tags sqlite")
+	require.NoFileExists(t, dbFile)
+}
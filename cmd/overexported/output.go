@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/willabides/overexported/internal/overexported"
+)
+
+// outputFormats returns the formats accepted by --out.
+func outputFormats() []string {
+	return []string{"text", "json", "json-envelope", "markdown", "sarif"}
+}
+
+// outputModeFlags returns the output mode flags set in cli. Each selects a
+// different output, so at most one can be used. --json isn't among them: on
+// its own it selects JSON records, and it makes --stats and --packages-only
+// print JSON.
+func outputModeFlags(cli *cliOptions) []string {
+	var flags []string
+	add := func(set bool, flag string) {
+		if set {
+			flags = append(flags, flag)
+		}
+	}
+	add(cli.Format != "", "--format")
+	add(cli.Format == "" && cli.Preset != "", "--preset")
+	add(cli.JSONEnvelope, "--json-envelope")
+	add(cli.PackagesOnly, "--packages-only")
+	add(cli.Markdown, "--markdown")
+	add(cli.SARIF, "--sarif")
+	add(cli.NoHeaders, "--no-headers")
+	add(cli.Stats, "--stats")
+	add(len(cli.Out) > 0, "--out")
+	return flags
+}
+
+// checkOutputFlags returns an error if cli combines output flags that can't
+// be used together.
+func checkOutputFlags(cli *cliOptions) error {
+	modes := outputModeFlags(cli)
+	if len(modes) > 1 {
+		return fmt.Errorf("%s and %s can't be used together", modes[0], modes[1])
+	}
+	if cli.JSON && len(modes) == 1 && modes[0] != "--stats" && modes[0] != "--packages-only" {
+		return fmt.Errorf("--json and %s can't be used together", modes[0])
+	}
+	if cli.JSONFlatPosition && (!cli.JSON || len(modes) > 0) {
+		return fmt.Errorf("--json-flat-position requires --json without another output mode")
+	}
+	return nil
+}
+
+// outputSpec is a parsed --out value.
+type outputSpec struct {
+	format string
+	// dest is a filename, or "-" for stdout.
+	dest string
+}
+
+// parseOutputSpecs parses --out values of the form format:destination.
+func parseOutputSpecs(values []string) ([]outputSpec, error) {
+	specs := make([]outputSpec, 0, len(values))
+	for _, value := range values {
+		format, dest, ok := strings.Cut(value, ":")
+		if !ok || dest == "" {
+			return nil, fmt.Errorf("--out %q: want format:destination", value)
+		}
+		if !slices.Contains(outputFormats(), format) {
+			return nil, fmt.Errorf("--out %q: unknown format %q, want one of: %s", value, format, strings.Join(outputFormats(), ", "))
+		}
+		specs = append(specs, outputSpec{format: format, dest: dest})
+	}
+	return specs, nil
+}
+
+// writeOutputs writes result once for each spec, in order. Markdown links and
+// SARIF URIs are relative to root.
+func writeOutputs(stdout io.Writer, specs []outputSpec, result *overexported.Result, root string) error {
+	for _, spec := range specs {
+		err := writeOutput(stdout, spec, result, root)
+		if err != nil {
+			return fmt.Errorf("--out %s:%s: %w", spec.format, spec.dest, err)
+		}
+	}
+	return nil
+}
+
+func writeOutput(stdout io.Writer, spec outputSpec, result *overexported.Result, root string) error {
+	if spec.dest == "-" {
+		return printOutput(stdout, spec.format, result, root)
+	}
+	f, err := os.Create(spec.dest)
+	if err != nil {
+		return err
+	}
+	return errors.Join(printOutput(f, spec.format, result, root), f.Close())
+}
+
+func printOutput(w io.Writer, format string, result *overexported.Result, root string) error {
+	switch format {
+	case "json":
+		return printResultJSON(w, result)
+	case "json-envelope":
+		return printResultJSONEnvelope(w, result)
+	case "markdown":
+		return printResultMarkdown(w, result, root)
+	case "sarif":
+		return printResultSARIF(w, result, root)
+	default:
+		return printResult(w, result)
+	}
+}
@@ -4,13 +4,17 @@ import (
 	"bytes"
 	"cmp"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"maps"
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/willabides/overexported/internal/overexported"
@@ -25,35 +29,369 @@ program's main function. Any exported identifiers (functions, types, methods,
 variables, and constants) that are not referenced from outside their package
 are reported as over-exported, grouped by package.
 
+A reference counts as usage whether or not it is ever called. A method used as
+a method value (t.Method) or method expression (T.Method) from another package
+is used, even if the resulting func is never invoked, because unexporting the
+method would break that code. Method values taken inside the method's own
+package don't count, the same as calls.
+
 Packages are expressed in the notation of 'go list' (or other underlying build
 system if you are using an alternative golang.org/x/go/packages driver). Only
 executable (main) packages are considered starting points for the analysis.
+When the patterns select only part of a module (e.g. "./somepkg"), the whole
+enclosing module is still loaded so that usage from the rest of the module is
+seen. In a go.work workspace every workspace module is loaded, so usage from
+sibling modules counts too.
 
 The --test flag causes it to analyze test executables too. Tests sometimes make
 use of identifiers that would otherwise appear to be over-exported, and public
 API identifiers reported as over-exported with --test indicate possible gaps in
 your test coverage or truly unnecessary exports.
 
+Only files selected by the current build constraints are analyzed. Usage from
+files for other platforms or tag sets is not seen, so an export used only by a
+tag-selected alternative implementation is reported. The --tags flag sets the
+build tags to load with. Negative constraints are evaluated against them too,
+so a file with "//go:build !prod" is analyzed by default and left out with
+--tags=prod. The GOOS, GOARCH and tags used are recorded under "buildConfig"
+in --json-envelope output. To cover several tag sets, save --json output from a
+run with each and combine them with "overexported merge --intersect", which
+keeps only identifiers reported by every run.
+
+The --include-all-tags flag is an exploratory mode for API behind feature
+tags. It adds every custom tag that a //go:build line in the analyzed packages
+requires to --tags, so files for experimental features are analyzed and their
+exports can be assessed. GOOS, GOARCH, compiler and version tags are left out,
+as are "ignore" and "tools". This is imperfect: tags meant to be mutually
+exclusive are all set at once, which can select conflicting files and make
+loading fail, and files excluded by a set tag ("//go:build !experimental")
+drop out of the analysis.
+
+The --build-flag flag passes a flag verbatim to the go command used to load
+packages, for build configurations that --tags doesn't cover. It can be
+repeated. Malformed flags are reported as errors from loading packages.
+
+Files built only with the "tools" tag, following the tools.go convention for
+pinning tool dependencies in go.mod, never count as usage. Their references
+only keep the tools in go.mod, so with --tags=tools an export referenced only
+from such a file is still reported.
+
+Functions with a cgo "//export" directive are called from C through generated
+code the analysis doesn't see, and cgo requires the Go name to match, so they
+are never reported.
+
+The --strict-test flag implies --test and also reports public API (exports in
+packages without an "internal" path element) whose only external users are
+tests. These are flagged as test coverage gaps: the API is exercised by tests
+but by nothing else in the program.
+
+The --internal-strict flag implies --test and also reports exports in packages
+with an "internal" path element whose only external users are tests. Internal
+packages are never a public contract, so test-only usage doesn't justify
+exporting from them.
+
+With --test, usage from an external test package (package foo_test) counts as
+external usage because it can only reach exported identifiers. Internal test
+files (package foo) never count. Use --no-external-test-as-external to treat
+external test packages as part of the package they test.
+
 The --filter flag restricts results to packages that match the provided regular
 expression; its default value is the special string "<module>" which matches
 the listed packages and any other packages belonging to the same modules. Use
---filter= to display all results.
+--filter= to display all results. In GOPATH mode, where there are no modules,
+"<module>" matches everything.
+
+Symlinks in the working directory and in -C are resolved before loading, so
+file paths in the output are real paths and relative paths are computed
+between real paths.
+
+The --unexported-receivers flag also reports exported methods declared on
+unexported types. Code outside the package can't name these types, so such
+methods are almost always safe to unexport unless they satisfy an interface.
+
+The --entrypoint-regex flag adds exported functions in the target packages
+whose names match the provided regular expression as entry points, alongside
+main functions. This suits frameworks that wire up functions by naming
+convention, such as a generated router calling every "Handle*" function.
+Matching functions are never reported. An overly broad pattern will hide
+genuinely dead code.
+
+The --hint-regex flag annotates reported identifiers whose names match the
+provided regular expression, such as '^(Internal|Debug|Unsafe)'. Methods are
+matched by their own name. Names like these suggest the identifier was never
+meant to be public, which makes it an especially good cleanup candidate. There
+is no default pattern.
+
+The --experimental-rename-suggestions flag adds an unexported name to each
+reported identifier, "suggestedName" in JSON output. Initialisms are kept in
+one case, so HTTPClient becomes httpClient rather than hTTPClient. No name is
+suggested when it would be a Go keyword or collide with a name already in
+scope: for methods, a field or method of the receiver type, and otherwise a
+predeclared identifier such as new or string or a name declared in the
+package. The suggestions may change between releases.
+
+The --undocumented-only flag restricts the report to exports whose declaration
+has no doc comment. These are the least likely to be intended as public API,
+which makes them the easiest to clean up first.
+
+The --ignore-deprecated flag leaves out exports whose doc comment has a
+paragraph starting with "Deprecated: ". They are already on a known removal
+path, so reporting them again is noise.
+
+The --collapse-methods flag omits methods from the report when their type is
+also reported. Unexporting the type takes care of its methods, so listing them
+separately is mostly noise for whole dead types.
+
+The --suggest-dedup flag notes reported functions that have the same signature
+as other reported functions in the same package. These are often copy-paste
+variants that could be consolidated rather than just unexported.
 
 The --exclude flag excludes packages matching the provided pattern from the
 results. Patterns use the same syntax as 'go list' (e.g., "./...",
 "github.com/foo/bar/..."). This flag can be specified multiple times.
 
+Usage is always found across every package in the main modules, whatever the
+package patterns and --exclude flags say. Those only select which packages are
+reported on. In particular, a generator in the same module run by
+"//go:generate go run ./cmd/gen" is a main package like any other, so the
+exports it uses are never reported, even when it is excluded.
+
+The --report flag adds findings that are not over-exported in the strict sense
+but may deserve a look. It can be specified multiple times.
+  examples-pkg: exports whose only external users are in packages with an
+    "example" or "examples" path element. Such usage keeps an export alive but
+    may not reflect real consumers.
+  mock-only: interfaces whose only references from other packages are in
+    files generated by mockgen, moq or mockery. Such an interface may exist
+    only so that it can be mocked in tests.
+  instantiations: lists the type arguments each exported generic function and
+    type is instantiated with across the program. This helps decide whether a
+    generic is more general than it needs to be. It is included in text output
+    and in --json-envelope output.
+  write-only-vars: lists exported variables that other packages assign to but
+    never read. These are often better unexported behind a setter. It is
+    included in text output and in --json-envelope output.
+  orphan-packages: lists target packages, other than main packages, that no
+    other package imports. All of their exports are over-exported and the
+    package itself may be abandoned. It is included in text output and in
+    --json-envelope output.
+  inventory: lists every exported identifier of the target packages with a
+    "status" of "used", "over-exported", "kept-generated" or "suppressed" and
+    the packages that use it. It can be large, so it is only included in
+    --json-envelope output.
+  interface-map: maps each exported type of the target packages to the named
+    interfaces it satisfies, from the analyzed packages and their
+    dependencies. This shows why methods that are never called directly are
+    kept. It is only included in --json-envelope output.
+  called-methods: for each exported type with methods that a function in
+    another package takes as a parameter, directly or by pointer, lists its
+    methods and those used by other packages. When only some are used, the
+    parameter could be a smaller interface. It is included in text output and
+    in --json-envelope output.
+  unused-params: notes reported functions and methods that take reported
+    types as parameters, directly or through pointers, slices, arrays, maps
+    or channels. The function and the types are dead API together, and
+    removing the function first frees the types.
+  no-external-implementers: notes reported interfaces that no package-level
+    type in another package implements. Such an interface isn't serving
+    outside implementers either. Every reported interface is checked against
+    every named type, which can be slow in large programs.
+  enum-groups: notes reported constants whose named type has other
+    constants in the same package, such as the values of an iota enum, with
+    the type's name. This shows which values of an otherwise used enum are
+    dead.
+  name-clashes: notes reported methods whose name is also the name of a
+    package-level identifier in the same package, such as T.Get and Get.
+    Unexporting one but not the other may add to or clear up confusion.
+
+The --used-only-by flag lists exports that are used outside their package, but
+only by packages matching the given package patterns, along with those users.
+They would become unused if the matching packages were extracted or deleted.
+The list is included in text output and in --json-envelope output.
+
+The --pending-deletion flag takes a file listing patterns of packages that are
+scheduled for deletion, one per line. Usage from those packages doesn't count,
+so the report predicts what will be over-exported once they are gone. Exports
+that are only used by them are flagged "willBeOrphaned" in JSON output. The
+packages' own exports are not reported.
+
+By default the analysis fails if any package contains errors. The
+--max-load-errors flag tolerates up to the given number of broken packages
+(negative for no limit). Broken packages, and packages that import them, are
+skipped and listed in the output. The --require-ssa flag makes the analysis fail
+if any target package couldn't be analyzed, skipped ones included, so that a
+clean result can't hide packages whose exports were never seen.
+
+The --staged flag reports only identifiers whose declaration is on a line
+added or changed in the git index, as shown by "git diff --cached". The whole
+program is still analyzed. This suits a pre-commit hook that should only nag
+about declarations being committed.
+
+The --keep-file flag names a file listing identifiers that should never be
+reported, one per line in the form "pkgpath.Name" or "pkgpath.Type.Method".
+Entries are exact matches. Blank lines and lines starting with "#" are ignored.
+
+The --external-usage-file flag names a file in the same format listing
+identifiers used by consumers the analysis can't see, such as downstream
+modules of a public library. Unlike --keep-file, which hides findings, these
+count as real usage by an unknown package, so --strict-test and
+--report=examples-pkg don't flag them either.
+
+The --debug-reasons flag shows, for each reported identifier, what every usage
+source found: cross-package calls in the call graph, type references in
+reachable code, references in type-checked syntax and, for types, runtime
+types. A reported identifier normally has nothing from any of them. This helps
+confirm a finding isn't a false positive from a missed detection path.
+
+The --sort-by flag sets the order of records: "name" (the default) sorts by
+package path and name, "position" by file and line, and "confidence" by the
+confidence score. --sort-desc reverses it, so --sort-by=confidence --sort-desc
+puts the safest changes first. JSON and --format output follow this order
+exactly, across packages. Text and Markdown output still group records by
+package, in path order, and use it within each package.
+
+Each reported identifier has a "confidence" score in JSON output, from 0 to 1,
+estimating how likely it is that unexporting it breaks nothing the analysis
+can't see. It starts at 1 for functions and constants, 0.9 for variables,
+which linker flags can set, 0.8 for types, which reflection can reach, and 0.6
+for methods, which may satisfy an interface only checked at run time, or 0.9
+for methods of unexported types. It is halved for each of a well-known
+dynamically checked method name such as String or MarshalJSON, possible
+template access, use by an ignored file and use by generated code with
+--ignore-generated-callers. It is multiplied by 0.8 for exports only used by
+unreachable code with --transitive, and by 0.7 for exports still used by
+examples, mocks, tests or packages pending deletion.
+
+The --only flag restricts the report to the named identifiers, in pkgpath.Name
+or pkgpath.Type.Method form, to answer whether specific exports are used
+outside their package. Usage is still gathered from the whole program. It is an
+error if a named identifier isn't an export of the target packages.
+
+The --no-headers flag changes the text output to one "file:line:col: name
+(kind)" line per record, without package headers, like compiler diagnostics.
+It is meant for tools that parse that format without a custom --format.
+
+The --markdown flag outputs a Markdown report with a total count and a table of
+findings per package, suitable for posting as a pull request comment. File
+links are relative to the root of the git repository enclosing the -C
+directory, or to that directory outside of one.
+
+The --sarif flag outputs a SARIF 2.1.0 log with one result per reported
+identifier, for code scanning services such as GitHub code scanning. Each kind
+of identifier has its own rule, such as "overexported/func". File URIs are
+relative to the root of the git repository enclosing the -C directory.
+
+The --out flag writes the result in a format to a destination, given as
+format:destination. The format is one of text, json, json-envelope, markdown
+or sarif and the destination is a filename or "-" for stdout. Repeat it to get
+several outputs from one analysis, such as a text log and a JSON artifact in
+CI: --out=text:- --out=json:report.json. It replaces the other output flags.
+
+The --check flag is the recommended way to run the tool in CI. It takes a file
+saved from an earlier run with --json or --json-envelope as a baseline, reports
+only identifiers that aren't in it, and exits with an error if there are any.
+Identifiers are matched by package, name and kind. Text output also lists
+baseline identifiers that are no longer reported, so the baseline can be
+refreshed to lock in the improvement.
+
+The --stats flag prints, per package and overall, the number of exported
+identifiers, how many are used outside their package, how many are
+over-exported, and the percentage that are used, as a table. Combined with
+--json it prints a JSON object instead. Exports kept because they are generated
+or listed in --keep-file count toward the total only. Tracked over time, the
+percentage is a measure of how much of the public API is actually needed.
+It always counts the whole API, so it can't be combined with --check or
+--staged.
+
+The --packages-only flag prints only the sorted paths of packages with at least
+one over-exported identifier, one per line. Combined with --json it prints a
+JSON array of strings.
+
+The --json-keys flag adds a "key" field to each JSON record holding the package
+path and name joined by ".", as used in --keep-file. It makes a ready primary
+key for deduplication and baselines.
+
+The --json-flat-position flag changes --json output to write each position as
+a single "file:line:col" string instead of an object with "file", "line" and
+"col" fields, which suits log ingestion and grep-style consumers. Output
+written this way can't be read back by "overexported merge" or --check.
+
+The --json-envelope flag wraps the JSON records in an object. The records are
+under "exports" and "meta.summary" holds counts per kind and per package.
+"meta.analyzedPackages" and "meta.targetPackages" list the packages that were
+loaded and the packages whose exports were considered, which documents the
+scope of a saved result. "meta.generatedAt" records when the analysis ran;
+set SOURCE_DATE_EPOCH to a Unix timestamp to fix it for reproducible output.
+
+The --sqlite flag also appends the reported identifiers to a "findings" table
+in a SQLite database, creating both as needed, so that counts can be queried
+across runs. Each row has the module, package, name, kind, file relative to
+the repository root, line and run time, the UTC "meta.generatedAt" in RFC 3339
+form. A run without findings adds no rows. The SQLite driver is only built in
+with the sqlite build tag (go install -tags sqlite), since it adds several
+megabytes to the binary.
+
+The --format (-f) flag formats each record with a Go text/template. The
+template is executed with an Export value (fields Name, Kind, PkgPath and
+Position with File, Line and Col) and may call "rel" to make a filename
+relative to the current directory. The --preset flag selects a built-in
+template: "github-actions" emits workflow warning commands, "vim-quickfix"
+emits file:line:col lines with absolute paths, and "relative" emits the same
+with relative paths. --format takes precedence over --preset.
+
+The --cpuprofile and --memprofile flags write CPU and heap profiles for use
+with "go tool pprof", to see whether loading, SSA construction or the usage
+scan dominates a slow run. They are written even when the analysis fails.
+
+The "overexported merge" subcommand combines saved --json or --json-envelope
+output from several runs, for instance one per GOOS/GOARCH. With --intersect it
+keeps identifiers reported by every run, which are safe to unexport in all of
+those configurations. With --union it keeps identifiers reported by any run.
+Run "overexported merge --help" for details.
+
+The "overexported apidiff OLD NEW" subcommand analyzes two checkouts of a
+module and reports exports that were used in OLD but are over-exported in NEW,
+and exports that are new in NEW. Run "overexported apidiff --help" for
+details.
+
+The "overexported selfcheck" subcommand analyzes the overexported module
+itself. Run it from a checkout of the module, or pass -C to point at one.
+
 Example: show all over-exported identifiers within a module:
 
   $ overexported --test ./...
 
 By default, the tool does not report exports in generated files, as determined
 by the special comment described in https://go.dev/s/generatedcode . Use the
---generated flag to include them.
+--generated flag to include them. Exports in files generated by stringer or
+enumer are never reported, even with --generated, because their String methods
+are usually reached through fmt.Stringer in ways the analysis misses.
+
+References from generated files always count as usage, so an export used only
+by generated code in another package is not reported. The
+--ignore-generated-callers flag makes the analysis stricter by not counting
+references from generated files. This catches exports kept alive only by code
+that is about to be regenerated, at the risk of reporting exports the generator
+genuinely needs. Exports that generated code uses are flagged "usedByGenerated"
+in JSON output.
+
+Templates (text/template and html/template) call methods by name at run time.
+Methods named as ".Method" in a template string passed to Parse are treated as
+used when a value of their type is passed to Execute or ExecuteTemplate. Other
+exported methods of such types are reported but flagged as possibly used by a
+template.
+
+Files with a "//go:build ignore" constraint, such as generators run with
+"go run", are not part of the build and don't count as usage. Exports they
+reference are still reported, but flagged as possibly used by an ignored file.
 
 Just because an identifier is reported as over-exported does not mean it is
 unconditionally safe to unexport it. For example, an over-exported function may
 be referenced by another over-exported function. Some judgement is required.
+The --transitive flag helps here: it ignores references from functions and
+methods that are unreachable from any main function, so an export used only by
+dead code is reported too, noting the dead code that uses it.
+--ignore-dead-callers is an alias for it.
 
 The analysis is valid only for a single GOOS/GOARCH configuration, so an
 identifier reported as over-exported may be used in a different configuration.
@@ -61,13 +399,56 @@ Consider running the tool once for each configuration of interest.
 `
 
 type cliOptions struct {
-	Chdir     string   `short:"C" help:"Change to this directory before running."`
-	Test      bool     `help:"Include test packages and executables in the analysis."`
-	Generated bool     `help:"Include exports in generated Go files."`
-	JSON      bool     `help:"Output JSON records."`
-	Filter    string   `default:"<module>" help:"Report only packages matching this regular expression. '<module>' matches the modules of all analyzed packages."`
-	Exclude   []string `help:"Exclude packages matching this pattern from the results. Can be specified multiple times."`
-	Packages  []string `arg:"" required:"" help:"Package patterns to analyze."`
+	Chdir                         string   `short:"C" help:"Change to this directory before running."`
+	Test                          bool     `help:"Include test packages and executables in the analysis."`
+	Tags                          []string `help:"Build tags to set when loading packages. Can be specified multiple times or comma-separated."`
+	IncludeAllTags                bool     `help:"Also set every custom build tag required by a //go:build line in the analyzed packages. Mutually exclusive tags may make loading fail."`
+	BuildFlag                     []string `sep:"none" help:"Flag to pass to the go command when loading packages, such as -gcflags=all=-N. Can be specified multiple times."`
+	InternalStrict                bool     `help:"Like --test, but also report exports in internal packages used only by tests."`
+	StrictTest                    bool     `help:"Like --test, but also report public API used only by tests, flagged as a test coverage gap."`
+	ExternalTestAsExternal        bool     `default:"true" negatable:"" help:"With --test, count usage from external test packages (foo_test) as external."`
+	Generated                     bool     `help:"Include exports in generated Go files."`
+	IgnoreGeneratedCallers        bool     `help:"Don't count references from generated files as usage."`
+	JSON                          bool     `help:"Output JSON records."`
+	Staged                        bool     `help:"Report only identifiers declared on lines staged in git."`
+	JSONKeys                      bool     `help:"Add a \"key\" field, pkgpath.Name, to each JSON record."`
+	JSONFlatPosition              bool     `help:"With --json, write each position as a \"file:line:col\" string instead of an object."`
+	JSONEnvelope                  bool     `help:"Output a JSON object with the records under \"exports\" and summary data under \"meta\"."`
+	Format                        string   `short:"f" help:"Format each record with this text/template."`
+	Preset                        string   `enum:",github-actions,vim-quickfix,relative" default:"" help:"Format records with a built-in template. One of: github-actions, vim-quickfix, relative."`
+	UnexportedReceivers           bool     `help:"Also report exported methods on unexported types."`
+	Filter                        string   `default:"<module>" help:"Report only packages matching this regular expression. '<module>' matches the modules of all analyzed packages."`
+	EntrypointRegex               string   `help:"Use exported functions in the target packages whose names match this regular expression as additional entry points."`
+	HintRegex                     string   `help:"Annotate reported identifiers whose names match this regular expression, such as '^(Internal|Debug|Unsafe)'."`
+	ExperimentalRenameSuggestions bool     `help:"Suggest an unexported name for each reported identifier."`
+	Transitive                    bool     `aliases:"ignore-dead-callers" help:"Also report exports only used by unreachable code, such as over-exported functions nothing calls."`
+	DebugReasons                  bool     `help:"Show what each usage source found for every reported identifier."`
+	Markdown                      bool     `help:"Output a Markdown report, suitable for a pull request comment."`
+	SARIF                         bool     `help:"Output a SARIF 2.1.0 log, for code scanning services."`
+	NoHeaders                     bool     `help:"In text output, print one file:line:col line per record without package headers."`
+	Out                           []string `sep:"none" placeholder:"FORMAT:DEST" help:"Write output in this format to this file, or to stdout for \"-\". One of: text, json, json-envelope, markdown, sarif. Can be specified multiple times."`
+	Stats                         bool     `help:"Print counts of exported, used and over-exported identifiers per package and overall instead of the records. With --json, print a JSON object."`
+	PackagesOnly                  bool     `help:"Print only the paths of packages with findings, one per line. With --json, print a JSON array."`
+	UndocumentedOnly              bool     `help:"Report only exports without a doc comment."`
+	IgnoreDeprecated              bool     `help:"Don't report exports whose doc comment has a \"Deprecated: \" paragraph."`
+	CollapseMethods               bool     `help:"Omit methods of types that are also reported."`
+	SuggestDedup                  bool     `help:"Note reported functions that share a signature with other reported functions in the same package."`
+	SortBy                        string   `enum:"name,position,confidence" default:"name" help:"Order records by this field. Text and Markdown output apply it within each package. One of: name, position, confidence."`
+	SortDesc                      bool     `help:"Reverse the --sort-by order."`
+	Only                          []string `help:"Report only these identifiers (pkgpath.Name or pkgpath.Type.Method). Can be specified multiple times or comma-separated."`
+	UsedOnlyBy                    []string `help:"List exports used outside their package only by packages matching this pattern, such as example.com/mono/legacy/... Can be specified multiple times."`
+	Exclude                       []string `help:"Exclude packages matching this pattern from the results. Can be specified multiple times."`
+	Report                        []string `enum:"examples-pkg,mock-only,instantiations,write-only-vars,orphan-packages,inventory,interface-map,called-methods,unused-params,no-external-implementers,enum-groups,name-clashes" help:"Additional reports to include. One of: examples-pkg, mock-only, instantiations, write-only-vars, orphan-packages, inventory, interface-map, called-methods, unused-params, no-external-implementers, enum-groups, name-clashes."`
+	MaxLoadErrors                 int      `help:"Number of packages with errors to tolerate. They are skipped along with packages that import them. Negative means no limit."`
+	RequireSSA                    bool     `help:"Fail if any target package couldn't be analyzed, such as one skipped by --max-load-errors."`
+	ExternalUsageFile             string   `type:"existingfile" help:"File with newline-delimited identifiers (pkgpath.Name) used by consumers outside the analyzed code."`
+	PendingDeletion               string   `type:"existingfile" help:"File with newline-delimited patterns of packages scheduled for deletion. Usage from them doesn't count."`
+	CPUProfile                    string   `name:"cpuprofile" type:"path" help:"Write a CPU profile to this file."`
+	MemProfile                    string   `name:"memprofile" type:"path" help:"Write a heap profile to this file when done."`
+	Check                         string   `type:"existingfile" help:"Report only identifiers missing from this saved --json or --json-envelope output, and fail if there are any."`
+	SQLite                        string   `name:"sqlite" type:"path" help:"Also append the reported identifiers to the findings table of this SQLite database. Requires a build with -tags sqlite."`
+	KeepFile                      string   `type:"existingfile" help:"File with newline-delimited identifiers (pkgpath.Name) to exclude from the results."`
+	Packages                      []string `arg:"" required:"" help:"Package patterns to analyze."`
 }
 
 func main() {
@@ -78,44 +459,396 @@ func main() {
 	}
 }
 
-func run(stdout io.Writer, args []string) error {
+func run(stdout io.Writer, args []string) (err error) {
+	handled, err := runSubcommand(stdout, args)
+	if handled {
+		return err
+	}
+	cli, outs, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	// Profiles are written by deferred calls so that they are flushed even
+	// when the analysis fails.
+	stopProfiles, err := startProfiles(cli)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, stopProfiles()) }()
+	in, err := readInputs(cli)
+	if err != nil {
+		return err
+	}
+	result, err := overexported.Run(cli.Packages, runOptions(cli, in, outs))
+	if err != nil {
+		return err
+	}
+	fixed, err := filterResult(cli, result, in.baseline)
+	if err != nil {
+		return err
+	}
+	err = writeResult(stdout, cli, outs, in.tmpl, result, fixed)
+	if err != nil {
+		return err
+	}
+	err = recordSQLite(cli, result)
+	if err != nil {
+		return err
+	}
+	return checkResult(cli, result)
+}
+
+// recordSQLite appends result to the --sqlite database, if one is set.
+func recordSQLite(cli *cliOptions, result *overexported.Result) error {
+	if cli.SQLite == "" {
+		return nil
+	}
+	return writeSQLite(cli.SQLite, result, repoRoot(cli.Chdir))
+}
+
+// checkResult returns an error for --check if result has exports that aren't
+// in the baseline.
+func checkResult(cli *cliOptions, result *overexported.Result) error {
+	if cli.Check != "" && len(result.Exports) > 0 {
+		return fmt.Errorf("found %d over-exported identifiers not in %s", len(result.Exports), cli.Check)
+	}
+	return nil
+}
+
+// runSubcommand runs the subcommand named by args[0], if there is one. It
+// returns false when args don't start with a subcommand.
+func runSubcommand(stdout io.Writer, args []string) (bool, error) {
+	if len(args) == 0 {
+		return false, nil
+	}
+	switch args[0] {
+	case "merge":
+		return true, runMerge(stdout, args[1:])
+	case "selfcheck":
+		return true, runSelfcheck(stdout, args[1:])
+	case "apidiff":
+		return true, runAPIDiff(stdout, args[1:])
+	default:
+		return false, nil
+	}
+}
+
+// parseArgs parses the command line for the default command. It returns an
+// error if flags are combined that can't be used together.
+func parseArgs(args []string) (*cliOptions, []outputSpec, error) {
 	var cli cliOptions
 	p, err := kong.New(&cli,
 		kong.Description(strings.TrimSpace(description)),
 	)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	_, err = p.Parse(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = checkOutputFlags(&cli)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Stats are counted from the inventory, which --check and --staged don't
+	// filter.
+	if cli.Stats && (cli.Check != "" || cli.Staged) {
+		return nil, nil, fmt.Errorf("--stats is not compatible with --check or --staged")
+	}
+	outs, err := parseOutputSpecs(cli.Out)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &cli, outs, nil
+}
+
+// startProfiles starts the CPU profile for --cpuprofile. The returned
+// function writes the heap profile for --memprofile and stops the CPU
+// profile.
+func startProfiles(cli *cliOptions) (func() error, error) {
+	stopCPU := func() error { return nil }
+	if cli.CPUProfile != "" {
+		var err error
+		stopCPU, err = startCPUProfile(cli.CPUProfile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return func() error {
+		var err error
+		if cli.MemProfile != "" {
+			err = writeMemProfile(cli.MemProfile)
+		}
+		return errors.Join(err, stopCPU())
+	}, nil
+}
+
+// cliInputs holds what the flags of a run name, read or parsed ahead of the
+// analysis.
+type cliInputs struct {
+	tmpl            *template.Template
+	keep            []string
+	baseline        []overexported.Export
+	externalUsage   []string
+	pendingDeletion []string
+}
+
+// readInputs parses the --format or --preset template and reads the files
+// named by --keep-file, --check, --external-usage-file and
+// --pending-deletion.
+func readInputs(cli *cliOptions) (*cliInputs, error) {
+	var in cliInputs
+	var err error
+	format := cmp.Or(cli.Format, presetFormat(cli.Preset))
+	if format != "" {
+		in.tmpl, err = parseFormat(format)
+		if err != nil {
+			return nil, err
+		}
+	}
+	in.keep, err = readOptionalIdentifierFile(cli.KeepFile)
+	if err != nil {
+		return nil, err
+	}
+	if cli.Check != "" {
+		in.baseline, err = readResultFile(cli.Check)
+		if err != nil {
+			return nil, err
+		}
+	}
+	in.externalUsage, err = readOptionalIdentifierFile(cli.ExternalUsageFile)
+	if err != nil {
+		return nil, err
+	}
+	in.pendingDeletion, err = readOptionalIdentifierFile(cli.PendingDeletion)
+	if err != nil {
+		return nil, err
+	}
+	return &in, nil
+}
+
+// runOptions returns the analysis options for cli.
+func runOptions(cli *cliOptions, in *cliInputs, outs []outputSpec) *overexported.Options {
+	return &overexported.Options{
+		Test:                   cli.Test,
+		Tags:                   cli.Tags,
+		AllTags:                cli.IncludeAllTags,
+		BuildFlags:             cli.BuildFlag,
+		StrictTest:             cli.StrictTest,
+		InternalStrict:         cli.InternalStrict,
+		ExternalTestAsExternal: cli.ExternalTestAsExternal,
+		Generated:              cli.Generated,
+		IgnoreGeneratedCallers: cli.IgnoreGeneratedCallers,
+		Filter:                 cli.Filter,
+		Exclude:                cli.Exclude,
+		UsedOnlyBy:             cli.UsedOnlyBy,
+		PendingDeletion:        in.pendingDeletion,
+		NameHintPattern:        cli.HintRegex,
+		RenameSuggestions:      cli.ExperimentalRenameSuggestions,
+		EntrypointPattern:      cli.EntrypointRegex,
+		SuggestDedup:           cli.SuggestDedup,
+		CollapseMethods:        cli.CollapseMethods,
+		UndocumentedOnly:       cli.UndocumentedOnly,
+		IgnoreDeprecated:       cli.IgnoreDeprecated,
+		DebugReasons:           cli.DebugReasons,
+		Transitive:             cli.Transitive,
+		Dir:                    cli.Chdir,
+		UnexportedReceivers:    cli.UnexportedReceivers,
+		Keep:                   in.keep,
+		ExternalUsage:          in.externalUsage,
+		Only:                   cli.Only,
+		MaxLoadErrors:          cli.MaxLoadErrors,
+		RequireSSA:             cli.RequireSSA,
+		Now:                    sourceDateEpochNow(os.Getenv),
+		ExamplesOnly:           slices.Contains(cli.Report, "examples-pkg"),
+		MockOnly:               slices.Contains(cli.Report, "mock-only"),
+		Instantiations:         slices.Contains(cli.Report, "instantiations"),
+		WriteOnlyVars:          slices.Contains(cli.Report, "write-only-vars"),
+		OrphanPackages:         slices.Contains(cli.Report, "orphan-packages"),
+		BuildConfig:            cli.JSONEnvelope || slices.ContainsFunc(outs, func(out outputSpec) bool { return out.format == "json-envelope" }),
+		Inventory:              cli.Stats || slices.Contains(cli.Report, "inventory"),
+		InterfaceMap:           slices.Contains(cli.Report, "interface-map"),
+		CalledMethods:          slices.Contains(cli.Report, "called-methods"),
+		UnusedParams:           slices.Contains(cli.Report, "unused-params"),
+		NoExternalImplementers: slices.Contains(cli.Report, "no-external-implementers"),
+		EnumGroups:             slices.Contains(cli.Report, "enum-groups"),
+		NameClashes:            slices.Contains(cli.Report, "name-clashes"),
+	}
+}
+
+// filterResult applies --check and --staged to result, adds keys for
+// --json-keys and sorts the exports for --sort-by. It returns the baseline
+// exports that are no longer reported. The baseline is applied first, since
+// exports left out by --staged are still reported.
+func filterResult(cli *cliOptions, result *overexported.Result, baseline []overexported.Export) ([]overexported.Export, error) {
+	var fixed []overexported.Export
+	if cli.Check != "" {
+		fixed = applyBaseline(result, baseline)
+	}
+	if cli.Staged {
+		lines, err := stagedLines(cli.Chdir)
+		if err != nil {
+			return nil, err
+		}
+		result.Exports = filterStaged(result.Exports, lines)
+	}
+	if cli.JSONKeys {
+		result.SetKeys()
+	}
+	sortExports(result.Exports, cli.SortBy, cli.SortDesc)
+	return fixed, nil
+}
+
+// writeResult writes result in the output selected by cli's flags. Text
+// output lists the baseline exports in fixed.
+func writeResult(stdout io.Writer, cli *cliOptions, outs []outputSpec, tmpl *template.Template, result *overexported.Result, fixed []overexported.Export) error {
+	switch {
+	case len(outs) > 0:
+		return writeOutputs(stdout, outs, result, repoRoot(cli.Chdir))
+	case cli.Stats:
+		return printStats(stdout, result, cli.JSON)
+	case cli.PackagesOnly:
+		return printPackages(stdout, result, cli.JSON)
+	case cli.Markdown:
+		return printResultMarkdown(stdout, result, repoRoot(cli.Chdir))
+	case cli.SARIF:
+		return printResultSARIF(stdout, result, repoRoot(cli.Chdir))
+	case cli.JSON || cli.JSONEnvelope:
+		return writeJSONResult(stdout, cli, result)
+	default:
+		return writeTextResult(stdout, cli, tmpl, result, fixed)
+	}
+}
+
+// writeJSONResult writes result as JSON in the shape selected by
+// --json-envelope and --json-flat-position.
+func writeJSONResult(stdout io.Writer, cli *cliOptions, result *overexported.Result) error {
+	switch {
+	case cli.JSONEnvelope:
+		return printResultJSONEnvelope(stdout, result)
+	case cli.JSONFlatPosition:
+		return printResultJSONFlatPosition(stdout, result)
+	default:
+		return printResultJSON(stdout, result)
+	}
+}
+
+// writeTextResult writes result as text: with tmpl when it is set, flat with
+// --no-headers, and otherwise grouped by package followed by the baseline
+// exports in fixed.
+func writeTextResult(stdout io.Writer, cli *cliOptions, tmpl *template.Template, result *overexported.Result, fixed []overexported.Export) error {
+	switch {
+	case tmpl != nil:
+		return printResultTemplate(stdout, tmpl, result)
+	case cli.NoHeaders:
+		return printResultFlat(stdout, result)
+	}
+	err := printResult(stdout, result)
 	if err != nil {
 		return err
 	}
-	result, err := overexported.Run(cli.Packages, &overexported.Options{
-		Test:      cli.Test,
-		Generated: cli.Generated,
-		Filter:    cli.Filter,
-		Exclude:   cli.Exclude,
-		Dir:       cli.Chdir,
+	_, err = io.WriteString(stdout, fixedSection(fixed))
+	return err
+}
+
+// sortExports sorts exports by sortBy: "name" (package path then name),
+// "position" or "confidence". Ties are broken by package path and name. Text
+// and Markdown output keep packages in path order and use this order within
+// each package.
+func sortExports(exports []overexported.Export, sortBy string, desc bool) {
+	slices.SortFunc(exports, func(a, b overexported.Export) int {
+		var c int
+		switch sortBy {
+		case "position":
+			c = cmp.Or(
+				cmp.Compare(a.Position.File, b.Position.File),
+				cmp.Compare(a.Position.Line, b.Position.Line),
+				cmp.Compare(a.Position.Col, b.Position.Col),
+			)
+		case "confidence":
+			c = cmp.Compare(a.Confidence, b.Confidence)
+		}
+		if desc {
+			c = -c
+		}
+		byName := cmp.Or(cmp.Compare(a.PkgPath, b.PkgPath), cmp.Compare(a.Name, b.Name))
+		if desc && sortBy == "name" {
+			byName = -byName
+		}
+		return cmp.Or(c, byName)
 	})
+}
+
+// printPackages prints the sorted paths of packages with at least one
+// over-exported identifier, one per line or as a JSON array.
+func printPackages(stdout io.Writer, result *overexported.Result, asJSON bool) error {
+	pkgs := []string{}
+	for _, exp := range result.Exports {
+		pkgs = append(pkgs, exp.PkgPath)
+	}
+	slices.Sort(pkgs)
+	pkgs = slices.Compact(pkgs)
+	if asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(pkgs)
+	}
+	for _, pkg := range pkgs {
+		_, err := fmt.Fprintln(stdout, pkg)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sourceDateEpochNow returns a clock fixed at $SOURCE_DATE_EPOCH when it is set
+// to a valid Unix timestamp, following https://reproducible-builds.org/specs/source-date-epoch/ .
+// Otherwise it returns nil so the analysis uses the current time.
+func sourceDateEpochNow(getenv func(string) string) func() time.Time {
+	epoch, err := strconv.ParseInt(getenv("SOURCE_DATE_EPOCH"), 10, 64)
 	if err != nil {
-		return err
+		return nil
+	}
+	t := time.Unix(epoch, 0).UTC()
+	return func() time.Time { return t }
+}
+
+// readOptionalIdentifierFile reads filename like readIdentifierFile, or
+// returns nil if filename is empty.
+func readOptionalIdentifierFile(filename string) ([]string, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	return readIdentifierFile(filename)
+}
+
+// readIdentifierFile reads newline-delimited identifiers from a file such as
+// a keep file. Blank lines and lines starting with "#" are ignored.
+func readIdentifierFile(filename string) ([]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("read identifier file: %w", err)
 	}
-	if !cli.JSON {
-		return printResult(stdout, result)
+	var keep []string
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keep = append(keep, line)
 	}
-	return printResultJSON(stdout, result)
+	return keep, nil
 }
 
 func printResult(stdout io.Writer, result *overexported.Result) error {
 	if len(result.Exports) == 0 {
-		_, err := fmt.Fprintln(stdout, "No over-exported identifiers found.")
+		_, err := fmt.Fprintf(stdout, "No over-exported identifiers found.\n%s%s%s%s%s%s", instantiationsSection(result), writeOnlyVarsSection(result), orphanPackagesSection(result), usedOnlyBySection(result), calledMethodsSection(result), skippedNote(result))
 		return err
 	}
 
-	cwd, err := os.Getwd()
-	if err != nil {
-		cwd = ""
-	}
+	cwd := workingDir()
 
 	// Group by package
 	byPkg := make(map[string][]overexported.Export)
@@ -128,21 +861,290 @@ func printResult(stdout io.Writer, result *overexported.Result) error {
 		fmt.Fprintf(&buf, "\n%s:\n", pkg)
 		fmt.Fprintln(&buf, "  Can be unexported (only used internally):")
 
-		slices.SortFunc(byPkg[pkg], func(a, b overexported.Export) int {
-			return cmp.Compare(a.Name, b.Name)
-		})
 		for _, exp := range byPkg[pkg] {
 			relPath, relErr := filepath.Rel(cwd, exp.Position.File)
 			if relErr != nil {
 				relPath = exp.Position.File
 			}
-			fmt.Fprintf(&buf, "    %s (%s) ./%s:%d\n", exp.Name, exp.Kind, relPath, exp.Position.Line)
+			fmt.Fprintf(&buf, "    %s (%s) ./%s:%d%s\n", exp.Name, exp.Kind, relPath, exp.Position.Line, exportNotes(exp))
+			if len(exp.Reasons) > 0 {
+				fmt.Fprintf(&buf, "      %s\n", strings.Join(exp.Reasons, "; "))
+			}
 		}
 	}
-	_, err = stdout.Write(buf.Bytes())
+	buf.WriteString(instantiationsSection(result))
+	buf.WriteString(writeOnlyVarsSection(result))
+	buf.WriteString(orphanPackagesSection(result))
+	buf.WriteString(usedOnlyBySection(result))
+	buf.WriteString(calledMethodsSection(result))
+	buf.WriteString(skippedNote(result))
+	_, err := stdout.Write(buf.Bytes())
 	return err
 }
 
+// printResultFlat prints one "file:line:col: name (kind)" line per export,
+// like compiler diagnostics, with no package headers. Paths are relative to
+// the working directory. Nothing is printed for an empty result other than
+// any extra report sections.
+func printResultFlat(stdout io.Writer, result *overexported.Result) error {
+	cwd := workingDir()
+	var buf bytes.Buffer
+	for _, exp := range result.Exports {
+		relPath, relErr := filepath.Rel(cwd, exp.Position.File)
+		if relErr != nil {
+			relPath = exp.Position.File
+		}
+		fmt.Fprintf(&buf, "%s:%d:%d: %s (%s)%s\n", relPath, exp.Position.Line, exp.Position.Col, exp.Name, exp.Kind, exportNotes(exp))
+	}
+	buf.WriteString(instantiationsSection(result))
+	buf.WriteString(writeOnlyVarsSection(result))
+	buf.WriteString(orphanPackagesSection(result))
+	buf.WriteString(usedOnlyBySection(result))
+	buf.WriteString(calledMethodsSection(result))
+	buf.WriteString(skippedNote(result))
+	_, err := stdout.Write(buf.Bytes())
+	return err
+}
+
+// instantiationsSection returns the text output for Result.Instantiations.
+func instantiationsSection(result *overexported.Result) string {
+	if len(result.Instantiations) == 0 {
+		return ""
+	}
+	var buf strings.Builder
+	buf.WriteString("\nInstantiations of exported generics:\n")
+	for _, inst := range result.Instantiations {
+		fmt.Fprintf(&buf, "  %s.%s: [%s]\n", inst.PkgPath, inst.Name, strings.Join(inst.TypeArgs, "], ["))
+	}
+	return buf.String()
+}
+
+// writeOnlyVarsSection returns the text output for Result.WriteOnlyVars.
+func writeOnlyVarsSection(result *overexported.Result) string {
+	if len(result.WriteOnlyVars) == 0 {
+		return ""
+	}
+	var buf strings.Builder
+	buf.WriteString("\nExported variables only written by other packages:\n")
+	for _, v := range result.WriteOnlyVars {
+		fmt.Fprintf(&buf, "  %s.%s: written by %s\n", v.PkgPath, v.Name, strings.Join(v.Writers, ", "))
+	}
+	return buf.String()
+}
+
+// orphanPackagesSection returns the text output for Result.OrphanPackages.
+func orphanPackagesSection(result *overexported.Result) string {
+	if len(result.OrphanPackages) == 0 {
+		return ""
+	}
+	var buf strings.Builder
+	buf.WriteString("\nPackages not imported by any other package:\n")
+	for _, pkg := range result.OrphanPackages {
+		fmt.Fprintf(&buf, "  %s\n", pkg)
+	}
+	return buf.String()
+}
+
+// usedOnlyBySection returns the text output for Result.UsedOnlyBy.
+func usedOnlyBySection(result *overexported.Result) string {
+	if len(result.UsedOnlyBy) == 0 {
+		return ""
+	}
+	var buf strings.Builder
+	buf.WriteString("\nExports used only by --used-only-by packages:\n")
+	for _, entry := range result.UsedOnlyBy {
+		fmt.Fprintf(&buf, "  %s.%s (%s): used by %s\n", entry.PkgPath, entry.Name, entry.Kind, strings.Join(entry.UsedBy, ", "))
+	}
+	return buf.String()
+}
+
+// calledMethodsSection returns the text output for Result.CalledMethods.
+func calledMethodsSection(result *overexported.Result) string {
+	if len(result.CalledMethods) == 0 {
+		return ""
+	}
+	var buf strings.Builder
+	buf.WriteString("\nMethods used by other packages of types taken as parameters:\n")
+	for _, cm := range result.CalledMethods {
+		called := strings.Join(cm.CalledByExternal, ", ")
+		if called == "" {
+			called = "none"
+		}
+		fmt.Fprintf(&buf, "  %s: %s (%d of %d)\n", cm.Type, called, len(cm.CalledByExternal), len(cm.Methods))
+	}
+	return buf.String()
+}
+
+// skippedNote returns a line reporting packages skipped due to errors, or an
+// empty string if none were skipped.
+func skippedNote(result *overexported.Result) string {
+	if len(result.SkippedPackages) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\nSkipped %d packages due to errors: %s\n",
+		len(result.SkippedPackages), strings.Join(result.SkippedPackages, ", "))
+}
+
+// exportNotes returns a bracketed list of annotations for text output.
+func exportNotes(exp overexported.Export) string {
+	candidates := []struct {
+		set  bool
+		note string
+	}{
+		{exp.UnreachableReceiver, "unreachable receiver"},
+		{exp.ExamplesOnly, "only used by examples"},
+		{exp.MockOnlyInterface, "only used by mocks"},
+		{exp.WillBeOrphaned, "only used by packages pending deletion"},
+		{exp.TestCoverageGap, "only used by tests"},
+		{exp.InternalStrict, "internal, only used by tests"},
+		{exp.MaybeTemplateAccessed, "maybe used by a template"},
+		{exp.MaybeUsedByIgnoredFile, "maybe used by an ignored file"},
+		{exp.UsedByGenerated, "used by generated code"},
+		{len(exp.OnlyUsedByUnreachable) > 0, "only used by unreachable " + strings.Join(exp.OnlyUsedByUnreachable, " and ")},
+		{exp.NameHint, "name suggests internal use"},
+		{exp.SuggestedName != "", "rename to " + exp.SuggestedName},
+		{exp.EnumGroup != "", "part of " + exp.EnumGroup + " enum"},
+		{exp.NoExternalImplementers, "no external implementers"},
+		{exp.SharesTopLevelName, "shares a name with a package-level identifier"},
+		{len(exp.DeadParamTypes) > 0, "takes reported " + strings.Join(exp.DeadParamTypes, " and ")},
+		{len(exp.PossibleDuplicates) > 0, "possible duplicate of " + strings.Join(exp.PossibleDuplicates, " and ")},
+	}
+	var notes []string
+	for _, c := range candidates {
+		if c.set {
+			notes = append(notes, c.note)
+		}
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(notes, ", ") + "]"
+}
+
+// presetFormat returns the built-in template for a --preset name.
+func presetFormat(name string) string {
+	switch name {
+	case "github-actions":
+		return `::warning file={{rel .Position.File}},line={{.Position.Line}},col={{.Position.Col}}::{{.Name}} ({{.Kind}}) can be unexported`
+	case "vim-quickfix":
+		return `{{.Position.File}}:{{.Position.Line}}:{{.Position.Col}}: {{.Name}} ({{.Kind}}) can be unexported`
+	case "relative":
+		return `{{rel .Position.File}}:{{.Position.Line}}:{{.Position.Col}}: {{.Name}} ({{.Kind}}) can be unexported`
+	default:
+		return ""
+	}
+}
+
+// parseFormat parses a --format template. Templates can call "rel" to make a
+// filename relative to the current directory.
+func parseFormat(format string) (*template.Template, error) {
+	cwd := workingDir()
+	tmpl, err := template.New("format").Funcs(template.FuncMap{
+		"rel": func(filename string) string {
+			relPath, relErr := filepath.Rel(cwd, filename)
+			if relErr != nil {
+				return filename
+			}
+			return relPath
+		},
+	}).Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format: %w", err)
+	}
+	return tmpl, nil
+}
+
+// printResultTemplate executes tmpl once per export, writing a newline after
+// each.
+func printResultTemplate(stdout io.Writer, tmpl *template.Template, result *overexported.Result) error {
+	var buf bytes.Buffer
+	for _, exp := range result.Exports {
+		err := tmpl.Execute(&buf, exp)
+		if err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+	}
+	_, err := stdout.Write(buf.Bytes())
+	return err
+}
+
+// printResultMarkdown writes a Markdown report of result with a section per
+// package. File links are relative to root.
+func printResultMarkdown(stdout io.Writer, result *overexported.Result, root string) error {
+	byPkg := make(map[string][]overexported.Export)
+	for _, exp := range result.Exports {
+		byPkg[exp.PkgPath] = append(byPkg[exp.PkgPath], exp)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "## Over-exported identifiers (%d)\n", len(result.Exports))
+	if len(result.Exports) == 0 {
+		buf.WriteString("\nNo over-exported identifiers found.\n")
+	}
+	for _, pkg := range slices.Sorted(maps.Keys(byPkg)) {
+		fmt.Fprintf(&buf, "\n### `%s`\n\n", pkg)
+		buf.WriteString("| Identifier | Kind | Location |\n")
+		buf.WriteString("| --- | --- | --- |\n")
+		for _, exp := range byPkg[pkg] {
+			relPath, err := filepath.Rel(root, exp.Position.File)
+			if err != nil {
+				relPath = exp.Position.File
+			}
+			relPath = filepath.ToSlash(relPath)
+			fmt.Fprintf(&buf, "| `%s`%s | %s | [%s:%d](%s#L%d) |\n",
+				exp.Name, exportNotes(exp), exp.Kind, relPath, exp.Position.Line, relPath, exp.Position.Line)
+		}
+	}
+	_, err := stdout.Write(buf.Bytes())
+	return err
+}
+
+// workingDir returns the current directory with symlinks resolved, to match
+// the positions in results, or "" if it can't be determined.
+func workingDir() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	resolved, err := filepath.EvalSymlinks(cwd)
+	if err != nil {
+		return cwd
+	}
+	return resolved
+}
+
+// repoRoot returns the nearest directory at or above start containing .git,
+// or start if there is none. An empty start means the current directory.
+// Symlinks are resolved to match the positions in results.
+func repoRoot(start string) string {
+	if start == "" {
+		start = workingDir()
+		if start == "" {
+			return ""
+		}
+	} else {
+		abs, err := filepath.Abs(start)
+		if err != nil {
+			return ""
+		}
+		start = abs
+		resolved, err := filepath.EvalSymlinks(abs)
+		if err == nil {
+			start = resolved
+		}
+	}
+	for dir := start; ; dir = filepath.Dir(dir) {
+		_, err := os.Stat(filepath.Join(dir, ".git"))
+		if err == nil {
+			return dir
+		}
+		if filepath.Dir(dir) == dir {
+			return start
+		}
+	}
+}
+
 func printResultJSON(stdout io.Writer, result *overexported.Result) error {
 	exports := result.Exports
 	if exports == nil {
@@ -152,3 +1154,83 @@ func printResultJSON(stdout io.Writer, result *overexported.Result) error {
 	enc.SetIndent("", "  ")
 	return enc.Encode(exports)
 }
+
+// flatPositionExport is an Export with its position as a string.
+type flatPositionExport struct {
+	overexported.Export
+	Position string `json:"position"`
+}
+
+// printResultJSONFlatPosition prints the exports like printResultJSON, but
+// with each position in "file:line:col" form.
+func printResultJSONFlatPosition(stdout io.Writer, result *overexported.Result) error {
+	exports := make([]flatPositionExport, 0, len(result.Exports))
+	for _, exp := range result.Exports {
+		exports = append(exports, flatPositionExport{Export: exp, Position: exp.Position.String()})
+	}
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(exports)
+}
+
+type jsonEnvelope struct {
+	Meta           jsonMeta                      `json:"meta"`
+	Exports        []overexported.Export         `json:"exports"`
+	Instantiations []overexported.Instantiation  `json:"instantiations,omitempty"`
+	WriteOnlyVars  []overexported.WriteOnlyVar   `json:"writeOnlyVars,omitempty"`
+	OrphanPackages []string                      `json:"orphanPackages,omitempty"`
+	Inventory      []overexported.InventoryEntry `json:"inventory,omitempty"`
+	UsedOnlyBy     []overexported.InventoryEntry `json:"usedOnlyBy,omitempty"`
+	InterfaceMap   map[string][]string           `json:"interfaceMap,omitempty"`
+	CalledMethods  []overexported.CalledMethods  `json:"calledMethods,omitempty"`
+}
+
+type jsonMeta struct {
+	GeneratedAt      time.Time                 `json:"generatedAt"`
+	SkippedPackages  []string                  `json:"skippedPackages,omitempty"`
+	AnalyzedPackages []string                  `json:"analyzedPackages,omitempty"`
+	TargetPackages   []string                  `json:"targetPackages,omitempty"`
+	BuildConfig      *overexported.BuildConfig `json:"buildConfig,omitempty"`
+	Summary          jsonSummary               `json:"summary"`
+}
+
+type jsonSummary struct {
+	Total     int            `json:"total"`
+	ByKind    map[string]int `json:"byKind"`
+	ByPackage map[string]int `json:"byPackage"`
+}
+
+func printResultJSONEnvelope(stdout io.Writer, result *overexported.Result) error {
+	env := jsonEnvelope{
+		Meta: jsonMeta{
+			GeneratedAt:      result.GeneratedAt,
+			SkippedPackages:  result.SkippedPackages,
+			AnalyzedPackages: result.AnalyzedPackages,
+			TargetPackages:   result.TargetPackages,
+			BuildConfig:      result.BuildConfig,
+			Summary: jsonSummary{
+				Total:     len(result.Exports),
+				ByKind:    make(map[string]int),
+				ByPackage: make(map[string]int),
+			},
+		},
+		Exports:        result.Exports,
+		Instantiations: result.Instantiations,
+		WriteOnlyVars:  result.WriteOnlyVars,
+		OrphanPackages: result.OrphanPackages,
+		Inventory:      result.Inventory,
+		UsedOnlyBy:     result.UsedOnlyBy,
+		InterfaceMap:   result.InterfaceMap,
+		CalledMethods:  result.CalledMethods,
+	}
+	if env.Exports == nil {
+		env.Exports = []overexported.Export{}
+	}
+	for _, exp := range result.Exports {
+		env.Meta.Summary.ByKind[exp.Kind]++
+		env.Meta.Summary.ByPackage[exp.PkgPath]++
+	}
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(env)
+}
@@ -3,12 +3,21 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"go/ast"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/willabides/overexported/internal/overexported"
+	"golang.org/x/tools/go/packages"
 )
 
 func runOverexported(t *testing.T, args ...string) (stdout string, _ error) {
@@ -63,6 +72,61 @@ func Test_run(t *testing.T) {
 				wantContains:    []string{"UnusedType", "UnusedType.UnusedTypeMethod", "UsedType.UnusedMethod"},
 				wantNotContains: []string{"UsedType", "UsedType.UsedMethod"},
 			},
+			{
+				name:            "constraint satisfaction",
+				dir:             "testdata/constraints",
+				args:            []string{"./..."},
+				wantContains:    []string{"Meters.Extra"},
+				wantNotContains: []string{"Number", "Meters", "Meters.Value"},
+			},
+			{
+				name:            "tools.go references don't count",
+				dir:             "testdata/toolsfile",
+				args:            []string{"--tags=tools", "./..."},
+				wantContains:    []string{"PinnedOnly"},
+				wantNotContains: []string{"Used"},
+			},
+			{
+				name:            "interfaces only used by mocks",
+				dir:             "testdata/mockonly",
+				args:            []string{"./..."},
+				wantNotContains: []string{"Store", "Cache", "Backend"},
+			},
+			{
+				name:            "mock-only report",
+				dir:             "testdata/mockonly",
+				args:            []string{"--report=mock-only", "./..."},
+				wantContains:    []string{"Store", "Cache"},
+				wantNotContains: []string{"Backend", "Memory"},
+			},
+			{
+				name:            "alias and definition receivers",
+				dir:             "testdata/aliasmethods",
+				args:            []string{"./..."},
+				wantContains:    []string{"A", "T.Direct", "T.UnusedViaAlias", "D.OnDefinition"},
+				wantNotContains: []string{"T", "D", "Ext", "T.ViaAlias", "A.ViaAlias", "A.Direct", "D.Direct", "Ext.Method"},
+			},
+			{
+				name:            "type registered with gob",
+				dir:             "testdata/gobregister",
+				args:            []string{"./..."},
+				wantContains:    []string{"Unused"},
+				wantNotContains: []string{"Event", "Name", "Count"},
+			},
+			{
+				name:            "references inside closures passed across packages",
+				dir:             "testdata/closurexref",
+				args:            []string{"./..."},
+				wantContains:    []string{"Unused"},
+				wantNotContains: []string{"Foo", "Bar", "Baz", "Value", "Callbacks", "Generic", "Sum"},
+			},
+			{
+				name:            "method values",
+				dir:             "testdata/methodvalues",
+				args:            []string{"./..."},
+				wantContains:    []string{"Thing.InternalValue", "Thing.Never"},
+				wantNotContains: []string{"Thing", "Thing.Called", "Thing.ExternalValue", "Thing.ExternalExpr"},
+			},
 			{
 				name:            "interface satisfaction",
 				dir:             "testdata/interfaces",
@@ -70,6 +134,13 @@ func Test_run(t *testing.T) {
 				wantContains:    []string{"Impl.UnusedImplMethod", "UnusedImpl", "UnusedImpl.DoSomething"},
 				wantNotContains: []string{"Impl", "Impl.Read"},
 			},
+			{
+				name:            "embedded interface satisfaction",
+				dir:             "testdata/embedifaces",
+				args:            []string{"./..."},
+				wantContains:    []string{"C.Bar"},
+				wantNotContains: []string{"C", "C.Foo", "C.Read", "Fooer"},
+			},
 			{
 				name:            "consts and vars",
 				dir:             "testdata/constvars",
@@ -91,12 +162,49 @@ func Test_run(t *testing.T) {
 				wantContains:    []string{"ManualUnused", "GeneratedUnused"},
 				wantNotContains: []string{"ManualUsed", "GeneratedUsed"},
 			},
+			{
+				name:            "references from generated files count",
+				dir:             "testdata/genregistry",
+				args:            []string{"./..."},
+				wantContains:    []string{"Unregistered"},
+				wantNotContains: []string{"Registered", "CalledFromGenerated"},
+			},
+			{
+				name:            "references from generated files count with --generated",
+				dir:             "testdata/genregistry",
+				args:            []string{"--generated", "./..."},
+				wantContains:    []string{"Unregistered"},
+				wantNotContains: []string{"Registered"},
+			},
+			{
+				// Registered is still used because main calls it through the
+				// function value, and that call isn't in a generated file.
+				name:            "references from generated files don't count with --ignore-generated-callers",
+				dir:             "testdata/genregistry",
+				args:            []string{"--ignore-generated-callers", "./..."},
+				wantContains:    []string{"Unregistered", "CalledFromGenerated"},
+				wantNotContains: []string{"Registered"},
+			},
+			{
+				name:            "stringer output is never reported",
+				dir:             "testdata/stringer",
+				args:            []string{"--generated", "./..."},
+				wantContains:    []string{"Green"},
+				wantNotContains: []string{"Color", "Color.String", "Red"},
+			},
 			{
 				name:            "generics",
 				dir:             "testdata/generics",
 				args:            []string{"./..."},
-				wantContains:    []string{"UnusedGeneric", "UnusedGenericType"},
-				wantNotContains: []string{"UsedGeneric", "UsedGenericType"},
+				wantContains:    []string{"UnusedGeneric", "UnusedGenericType", "UnusedGenericType.Get"},
+				wantNotContains: []string{"UsedGeneric", "UsedGenericType", "UsedGenericType.Get", "UsedWithLocalType"},
+			},
+			{
+				name:            "methods promoted through generic types",
+				dir:             "testdata/genericembed",
+				args:            []string{"./..."},
+				wantContains:    []string{"Base", "Base.UnusedInner", "Wrapper.UnusedGet"},
+				wantNotContains: []string{"Base.Inner", "Wrapper", "Wrapper.Get", "Wrapper.Inner", "PtrBase", "PtrBase.PtrInner", "PtrWrapper"},
 			},
 			{
 				name:            "type references",
@@ -112,6 +220,96 @@ func Test_run(t *testing.T) {
 				wantContains:    []string{"UnusedTimestamp", "UnusedString", "UnusedAsParam", "UnusedInStruct", "UnusedCounter"},
 				wantNotContains: []string{"Timestamp", "UsedString", "Now", "UsedAsParam", "UsedInStruct", "ProcessCount", "GetConfig", "Config", "MyCounter", "Counter", "Counter.Increment"},
 			},
+			{
+				name:            "types in recovered panic type switches",
+				dir:             "testdata/panictypes",
+				args:            []string{"./..."},
+				wantContains:    []string{"UnusedType"},
+				wantNotContains: []string{"RecoveredType", "PointerRecoveredType", "Boom"},
+			},
+			{
+				name:            "go:generate program in the module",
+				dir:             "testdata/gogenerate",
+				args:            []string{"./..."},
+				wantContains:    []string{"Unused"},
+				wantNotContains: []string{"Template", "Run"},
+			},
+			{
+				name:            "go:generate program outside the patterns",
+				dir:             "testdata/gogenerate",
+				args:            []string{"--exclude", "./cmd/gen", "./lib"},
+				wantContains:    []string{"Unused"},
+				wantNotContains: []string{"Template", "Run"},
+			},
+			{
+				name:            "functions in another package's composite literals",
+				dir:             "testdata/maplit",
+				args:            []string{"./..."},
+				wantContains:    []string{"Unused"},
+				wantNotContains: []string{"InMap", "InSlice", "InStruct"},
+			},
+			{
+				name:            "defer and go statements",
+				dir:             "testdata/deferredgo",
+				args:            []string{"./..."},
+				wantContains:    []string{"Unused"},
+				wantNotContains: []string{"Deferred", "Background", "Worker", "Worker.Close", "Worker.Start"},
+			},
+			{
+				name:            "test helper package used by several test packages",
+				dir:             "testdata/testhelpers",
+				args:            []string{"./..."},
+				wantContains:    []string{"Unused"},
+				wantNotContains: []string{"AssertOK", "UsedByInternalTest", "Foo", "Bar"},
+			},
+			{
+				name:            "test helper package without external tests as external",
+				dir:             "testdata/testhelpers",
+				args:            []string{"--no-external-test-as-external", "./..."},
+				wantContains:    []string{"Unused"},
+				wantNotContains: []string{"AssertOK", "UsedByInternalTest", "Foo", "Bar"},
+			},
+			{
+				name:            "generic declarations sharing names",
+				dir:             "testdata/genericnames",
+				args:            []string{"./..."},
+				wantContains:    []string{"B", "B.Get", "Get", "Other"},
+				wantNotContains: []string{"A", "A.Get", "Same"},
+			},
+			{
+				name:            "relative directory pattern",
+				dir:             "testdata/reldir",
+				args:            []string{"./a"},
+				wantContains:    []string{"AUnused"},
+				wantNotContains: []string{"AUsed", "BUnused", "BUsed"},
+			},
+			{
+				name:            "package directory inside module",
+				dir:             "testdata/reldir/a",
+				args:            []string{"."},
+				wantContains:    []string{"AUnused"},
+				wantNotContains: []string{"AUsed", "BUnused", "BUsed"},
+			},
+			{
+				name:            "relative ellipsis pattern",
+				dir:             "testdata/reldir",
+				args:            []string{"./b/..."},
+				wantContains:    []string{"BUnused"},
+				wantNotContains: []string{"AUnused", "AUsed", "BUsed"},
+			},
+			{
+				name:            "relative ellipsis pattern outside working directory",
+				dir:             "testdata/reldir/a",
+				args:            []string{"../b/..."},
+				wantContains:    []string{"BUnused"},
+				wantNotContains: []string{"AUnused", "AUsed", "BUsed"},
+			},
+			{
+				name:            "relative ellipsis pattern matching only main",
+				dir:             "testdata/foo",
+				args:            []string{"./cmd/..."},
+				wantNotContains: []string{"Bar"},
+			},
 			{
 				name:         "target pattern filtering",
 				dir:          "testdata/foo",
@@ -163,6 +361,11 @@ func Test_run(t *testing.T) {
 			// it's used by the external test package (lib_test), which is now
 			// treated as a separate package when --test is enabled.
 			assert.NotContains(t, names, "OnlyUsedInTests")
+
+			// OnlyUsedInInternalTest SHOULD be reported because the internal
+			// test files, even in the "lib [lib.test]" variant, are part of
+			// package lib.
+			assert.Contains(t, names, "OnlyUsedInInternalTest")
 		})
 
 		t.Run("without --test", func(t *testing.T) {
@@ -185,6 +388,37 @@ func Test_run(t *testing.T) {
 			// (it's only used by test files which are excluded)
 			assert.Contains(t, names, "OnlyUsedInTests")
 		})
+
+		t.Run("with --strict-test", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/external_test", "--json", "--strict-test", "./...")
+			require.NoError(t, err)
+
+			exports := parseJSONOutput(t, stdout)
+			gaps := make(map[string]bool)
+			for _, e := range exports {
+				gaps[e.Name] = e.TestCoverageGap
+			}
+			// OnlyUsedInTests is reported as a coverage gap, NotUsedInTests
+			// and OnlyUsedInInternalTest are plainly over-exported, and the rest
+			// are used by cmd/main.go.
+			assert.Equal(t, map[string]bool{"OnlyUsedInTests": true, "NotUsedInTests": false, "OnlyUsedInInternalTest": false}, gaps)
+		})
+
+		t.Run("with --test and --no-external-test-as-external", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/external_test", "--json", "--test", "--no-external-test-as-external", "./...")
+			require.NoError(t, err)
+
+			exports := parseJSONOutput(t, stdout)
+			names := exportNames(exports)
+
+			// Usage from lib_test no longer counts as external.
+			assert.Contains(t, names, "OnlyUsedInTests")
+			assert.Contains(t, names, "NotUsedInTests")
+			assert.NotContains(t, names, "UsedInExternalTest")
+			assert.NotContains(t, names, "UsedInInternalTest")
+		})
 	})
 
 	t.Run("filter", func(t *testing.T) {
@@ -280,6 +514,120 @@ func Test_run(t *testing.T) {
 		})
 	})
 
+	t.Run("examples-pkg report", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("without --report", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/examplespkg", "--json", "./...")
+			require.NoError(t, err)
+			names := exportNames(parseJSONOutput(t, stdout))
+			assert.Equal(t, []string{"Unused"}, names)
+		})
+
+		t.Run("with --report=examples-pkg", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/examplespkg", "--json", "--report=examples-pkg", "./...")
+			require.NoError(t, err)
+			exports := parseJSONOutput(t, stdout)
+			flagged := make(map[string]bool)
+			for _, e := range exports {
+				flagged[e.Name] = e.ExamplesOnly
+			}
+			assert.Equal(t, map[string]bool{"ExampleOnly": true, "Unused": false}, flagged)
+		})
+	})
+
+	t.Run("keep file", func(t *testing.T) {
+		t.Parallel()
+		keepFile := filepath.Join(t.TempDir(), "keep.txt")
+		err := os.WriteFile(keepFile, []byte("# kept on purpose\nconstvars.UnusedConst\n\nconstvars.UnusedFunc\nconstvars.Unused\n"), 0o600)
+		require.NoError(t, err)
+
+		stdout, err := runOverexported(t, "-C", "testdata/constvars", "--json", "--test", "--keep-file", keepFile, "./...")
+		require.NoError(t, err)
+		exports := parseJSONOutput(t, stdout)
+		names := exportNames(exports)
+		assert.Equal(t, []string{"UnusedVar"}, names)
+	})
+
+	t.Run("external usage file", func(t *testing.T) {
+		t.Parallel()
+		usageFile := filepath.Join(t.TempDir(), "usage.txt")
+		err := os.WriteFile(usageFile, []byte("# used downstream\nconstvars.UnusedConst\n"), 0o600)
+		require.NoError(t, err)
+
+		stdout, err := runOverexported(t, "-C", "testdata/constvars", "--json", "--test", "--external-usage-file", usageFile, "./...")
+		require.NoError(t, err)
+		names := exportNames(parseJSONOutput(t, stdout))
+		assert.NotContains(t, names, "UnusedConst")
+		assert.Contains(t, names, "UnusedVar")
+	})
+
+	t.Run("external usage of a mocked interface", func(t *testing.T) {
+		t.Parallel()
+		usageFile := filepath.Join(t.TempDir(), "usage.txt")
+		err := os.WriteFile(usageFile, []byte("mockonly/store.Store\n"), 0o600)
+		require.NoError(t, err)
+
+		stdout, err := runOverexported(t, "-C", "testdata/mockonly", "--json", "--report=mock-only", "--external-usage-file", usageFile, "./...")
+		require.NoError(t, err)
+		names := exportNames(parseJSONOutput(t, stdout))
+		assert.NotContains(t, names, "Store")
+		assert.Contains(t, names, "Cache")
+	})
+
+	t.Run("patterns matching no packages", func(t *testing.T) {
+		t.Parallel()
+		for _, pattern := range []string{"./typo", "./typo/..."} {
+			t.Run(pattern, func(t *testing.T) {
+				t.Parallel()
+				_, err := runOverexported(t, "-C", "testdata/foo", pattern)
+				require.EqualError(t, err, "no packages matched patterns: ["+pattern+"]")
+			})
+		}
+	})
+
+	t.Run("max load errors", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("errors by default", func(t *testing.T) {
+			t.Parallel()
+			_, err := runOverexported(t, "-C", "testdata/brokenpkg", "--json", "./...")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "packages contain errors")
+		})
+
+		t.Run("over the limit", func(t *testing.T) {
+			t.Parallel()
+			_, err := runOverexported(t, "-C", "testdata/brokenpkg", "--json", "--max-load-errors=1", "./...")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "2 packages contain errors, more than the maximum of 1")
+		})
+
+		for _, limit := range []string{"2", "-1"} {
+			t.Run("tolerated with limit "+limit, func(t *testing.T) {
+				t.Parallel()
+				stdout, err := runOverexported(t, "-C", "testdata/brokenpkg", "--json-envelope", "--max-load-errors="+limit, "./...")
+				require.NoError(t, err)
+				var env jsonEnvelope
+				require.NoError(t, json.Unmarshal([]byte(stdout), &env))
+				// Unused is only used by the broken package, which is skipped.
+				assert.Equal(t, []string{"Unused"}, exportNames(env.Exports))
+				assert.ElementsMatch(t, []string{"brokenpkg/broken", "brokenpkg/broken2"}, env.Meta.SkippedPackages)
+			})
+		}
+
+		t.Run("require ssa", func(t *testing.T) {
+			t.Parallel()
+			_, err := runOverexported(t, "-C", "testdata/brokenpkg", "--json", "--max-load-errors=-1", "--require-ssa", "./...")
+			require.EqualError(t, err, "target packages not built into SSA: brokenpkg/broken, brokenpkg/broken2")
+
+			_, err = runOverexported(t, "-C", "testdata/foo", "--json", "--require-ssa", "./...")
+			require.NoError(t, err)
+		})
+	})
+
 	t.Run("empty result", func(t *testing.T) {
 		t.Parallel()
 		stdout, err := runOverexported(t, "-C", "testdata/foo", "--json", "--test", "baz/foo/cmd/foo")
@@ -292,6 +640,90 @@ func Test_run(t *testing.T) {
 		assert.Empty(t, exports)
 	})
 
+	t.Run("json keys", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("with --json-keys", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/types", "--json", "--json-keys", "--test", "./...")
+			require.NoError(t, err)
+			exports := parseJSONOutput(t, stdout)
+			require.NotEmpty(t, exports)
+			for _, exp := range exports {
+				assert.Equal(t, exp.PkgPath+"."+exp.Name, exp.Key)
+			}
+		})
+
+		t.Run("without --json-keys", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/types", "--json", "--test", "./...")
+			require.NoError(t, err)
+			assert.NotContains(t, stdout, `"key"`)
+		})
+	})
+
+	t.Run("json envelope scope", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/reldir", "--json-envelope", "./a")
+		require.NoError(t, err)
+
+		var env jsonEnvelope
+		require.NoError(t, json.Unmarshal([]byte(stdout), &env))
+		assert.Equal(t, []string{"reldir/a", "reldir/b", "reldir/cmd"}, env.Meta.AnalyzedPackages)
+		assert.Equal(t, []string{"reldir/a"}, env.Meta.TargetPackages)
+		// AUsed's only user, reldir/cmd, isn't a target but is still loaded
+		// and counts as a user.
+		assert.Equal(t, []string{"AUnused"}, exportNames(env.Exports))
+	})
+
+	t.Run("json envelope", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/constvars", "--json-envelope", "--test", "./...")
+		require.NoError(t, err)
+
+		var env jsonEnvelope
+		require.NoError(t, json.Unmarshal([]byte(stdout), &env))
+		names := exportNames(env.Exports)
+		assert.Contains(t, names, "UnusedConst")
+		assert.Equal(t, len(env.Exports), env.Meta.Summary.Total)
+		assert.Equal(t, 1, env.Meta.Summary.ByKind["const"])
+		assert.Equal(t, 1, env.Meta.Summary.ByKind["var"])
+		assert.Equal(t, 1, env.Meta.Summary.ByKind["func"])
+		assert.Equal(t, 3, env.Meta.Summary.ByPackage["constvars"])
+		assert.False(t, env.Meta.GeneratedAt.IsZero())
+	})
+
+	t.Run("template access", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/templates", "--json", "./...")
+		require.NoError(t, err)
+
+		flagged := make(map[string]bool)
+		for _, e := range parseJSONOutput(t, stdout) {
+			flagged[e.Name] = e.MaybeTemplateAccessed
+		}
+		// Page.Heading is named in the template so it isn't reported.
+		assert.Equal(t, map[string]bool{
+			"Page.Footer":         true,
+			"Nav.Links":           true,
+			"NotRendered.Heading": false,
+		}, flagged)
+	})
+
+	t.Run("instantiations report", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/generics", "--json-envelope", "--report=instantiations", "./...")
+		require.NoError(t, err)
+
+		var env jsonEnvelope
+		require.NoError(t, json.Unmarshal([]byte(stdout), &env))
+		assert.Equal(t, []overexported.Instantiation{
+			{Name: "UsedGeneric", PkgPath: "generics", TypeArgs: []string{"int", "string"}},
+			{Name: "UsedGenericType", PkgPath: "generics", TypeArgs: []string{"string"}},
+			{Name: "UsedWithLocalType", PkgPath: "generics", TypeArgs: []string{"generics/cmd.local"}},
+		}, env.Instantiations)
+	})
+
 	t.Run("export fields", func(t *testing.T) {
 		t.Parallel()
 		stdout, err := runOverexported(t, "-C", "testdata/types", "--json", "--test", "./...")
@@ -313,26 +745,1523 @@ func Test_run(t *testing.T) {
 		assert.NotEmpty(t, exp.Position.File)
 		assert.Greater(t, exp.Position.Line, 0)
 		assert.Greater(t, exp.Position.Col, 0)
+		assert.InDelta(t, 0.8, exp.Confidence, 0.001)
 	})
 
-	t.Run("text output", func(t *testing.T) {
+	t.Run("confidence", func(t *testing.T) {
 		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/constvars", "--json", "--test", "./...")
+		require.NoError(t, err)
 
-		t.Run("with results", func(t *testing.T) {
+		exports := parseJSONOutput(t, stdout)
+		confidence := make(map[string]float64)
+		for _, e := range exports {
+			confidence[e.Name] = e.Confidence
+		}
+		assert.InDelta(t, 1.0, confidence["UnusedFunc"], 0.001)
+		assert.InDelta(t, 1.0, confidence["UnusedConst"], 0.001)
+		assert.InDelta(t, 0.9, confidence["UnusedVar"], 0.001)
+	})
+
+	t.Run("sort", func(t *testing.T) {
+		t.Parallel()
+		for _, tt := range []struct {
+			args []string
+			want []string
+		}{
+			{args: nil, want: []string{"UnusedConst", "UnusedFunc", "UnusedVar"}},
+			{args: []string{"--sort-by=position"}, want: []string{"UnusedConst", "UnusedVar", "UnusedFunc"}},
+			{args: []string{"--sort-by=position", "--sort-desc"}, want: []string{"UnusedFunc", "UnusedVar", "UnusedConst"}},
+			{args: []string{"--sort-by=confidence", "--sort-desc"}, want: []string{"UnusedConst", "UnusedFunc", "UnusedVar"}},
+		} {
+			args := append([]string{"-C", "testdata/constvars", "--json", "--test"}, tt.args...)
+			stdout, err := runOverexported(t, append(args, "./...")...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, exportNames(parseJSONOutput(t, stdout)), tt.args)
+		}
+	})
+
+	t.Run("unexported receivers", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("without --unexported-receivers", func(t *testing.T) {
 			t.Parallel()
-			stdout, err := runOverexported(t, "-C", "testdata/foo", "--test", "./...")
+			stdout, err := runOverexported(t, "-C", "testdata/receivers", "--json", "--test", "./...")
 			require.NoError(t, err)
+			exports := parseJSONOutput(t, stdout)
+			assert.Empty(t, exports)
+		})
 
-			assert.Contains(t, stdout, "baz/foo:")
-			assert.Contains(t, stdout, "Bar")
-			assert.Contains(t, stdout, "func")
+		t.Run("with --unexported-receivers", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/receivers", "--json", "--test", "--unexported-receivers", "./...")
+			require.NoError(t, err)
+			exports := parseJSONOutput(t, stdout)
+			require.Len(t, exports, 1)
+			assert.Equal(t, "internal.Export", exports[0].Name)
+			assert.Equal(t, "method", exports[0].Kind)
+			assert.True(t, exports[0].UnreachableReceiver)
+		})
+	})
+
+	t.Run("entrypoint regex", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("without --entrypoint-regex", func(t *testing.T) {
+			t.Parallel()
+			_, err := runOverexported(t, "-C", "testdata/entrypoints", "./...")
+			require.EqualError(t, err, "no main packages found")
 		})
 
-		t.Run("empty results", func(t *testing.T) {
+		t.Run("with --entrypoint-regex", func(t *testing.T) {
 			t.Parallel()
-			stdout, err := runOverexported(t, "-C", "testdata/foo", "--test", "baz/foo/cmd/foo")
+			stdout, err := runOverexported(t, "-C", "testdata/entrypoints", "--json", "--entrypoint-regex=^Handle", "./...")
 			require.NoError(t, err)
-			assert.Contains(t, stdout, "No over-exported identifiers found")
+			names := exportNames(parseJSONOutput(t, stdout))
+			assert.ElementsMatch(t, []string{"NotAHandler", "Unused"}, names)
 		})
+
+		t.Run("invalid pattern", func(t *testing.T) {
+			t.Parallel()
+			// The pattern is checked before loading, so the bad package
+			// pattern is never reached.
+			_, err := runOverexported(t, "-C", "testdata/entrypoints", "--entrypoint-regex=(", "./nonexistent")
+			require.ErrorContains(t, err, "invalid entrypoint pattern")
+		})
+	})
+
+	t.Run("write-only vars report", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/writeonly", "--json-envelope", "--report=write-only-vars", "./...")
+		require.NoError(t, err)
+
+		var env jsonEnvelope
+		require.NoError(t, json.Unmarshal([]byte(stdout), &env))
+		require.Len(t, env.WriteOnlyVars, 1)
+		assert.Equal(t, "WriteOnly", env.WriteOnlyVars[0].Name)
+		assert.Equal(t, "writeonly/lib", env.WriteOnlyVars[0].PkgPath)
+		assert.Equal(t, []string{"writeonly/cmd"}, env.WriteOnlyVars[0].Writers)
+	})
+
+	t.Run("dot imports", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/dotimport", "--json", "./...")
+		require.NoError(t, err)
+		var keys []string
+		for _, exp := range parseJSONOutput(t, stdout) {
+			keys = append(keys, exp.PkgPath+"."+exp.Name)
+		}
+		// The same names in dotimport/a are used through the dot-import.
+		assert.Equal(t, []string{"dotimport/c.Base", "dotimport/c.Base.Describe", "dotimport/c.Helper"}, keys)
+	})
+
+	t.Run("build tags", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		var files []string
+		for _, tt := range []struct {
+			tags string
+			want []string
+		}{
+			{tags: "", want: []string{"ProdEncode", "Backend"}},
+			{tags: "prod", want: []string{"DevEncode", "Backend"}},
+		} {
+			stdout, err := runOverexported(t, "-C", "testdata/buildtags", "--json", "--tags="+tt.tags, "./...")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, exportNames(parseJSONOutput(t, stdout)), tt.tags)
+			filename := filepath.Join(dir, "tags-"+tt.tags+".json")
+			require.NoError(t, os.WriteFile(filename, []byte(stdout), 0o600))
+			files = append(files, filename)
+		}
+		stdout, err := runOverexported(t, append([]string{"merge", "--intersect"}, files...)...)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Backend"}, exportNames(parseJSONOutput(t, stdout)))
+	})
+
+	t.Run("symlinked directory", func(t *testing.T) {
+		t.Parallel()
+		target, err := filepath.Abs("testdata/foo")
+		require.NoError(t, err)
+		link := filepath.Join(t.TempDir(), "foo")
+		require.NoError(t, os.Symlink(target, link))
+		stdout, err := runOverexported(t, "-C", link, "--test", "--preset=relative", "./...")
+		require.NoError(t, err)
+		assert.Equal(t, "testdata/foo/foo.go:7:6: Bar (func) can be unexported\n", stdout)
+	})
+
+	t.Run("cgo export", func(t *testing.T) {
+		t.Parallel()
+		out, err := exec.Command("go", "env", "CGO_ENABLED").Output()
+		if err != nil || strings.TrimSpace(string(out)) != "1" {
+			t.Skip("cgo is not available")
+		}
+		stdout, err := runOverexported(t, "-C", "testdata/cgoexport", "--json", "./...")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Unused"}, exportNames(parseJSONOutput(t, stdout)))
+	})
+
+	t.Run("profiles", func(t *testing.T) {
+		t.Parallel()
+		for _, tt := range []struct {
+			name    string
+			only    string
+			wantErr string
+		}{
+			{name: "success", only: "baz/foo.Bar"},
+			{name: "analysis error", only: "baz/foo.Nope", wantErr: "exported identifiers not found: baz/foo.Nope"},
+		} {
+			dir := t.TempDir()
+			cpuProfile := filepath.Join(dir, "cpu.pprof")
+			memProfile := filepath.Join(dir, "mem.pprof")
+			_, err := runOverexported(t, "-C", "testdata/foo", "--test", "--only", tt.only,
+				"--cpuprofile", cpuProfile, "--memprofile", memProfile, "./...")
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr, tt.name)
+			} else {
+				require.NoError(t, err, tt.name)
+			}
+			for _, filename := range []string{cpuProfile, memProfile} {
+				info, err := os.Stat(filename)
+				require.NoError(t, err, tt.name)
+				assert.Positive(t, info.Size(), tt.name)
+			}
+		}
+	})
+
+	t.Run("build flags", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("passed through", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/foo", "--json", "--test", "--build-flag=-ldflags=-X=main.a=b,c", "./...")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Bar"}, exportNames(parseJSONOutput(t, stdout)))
+		})
+
+		t.Run("malformed", func(t *testing.T) {
+			t.Parallel()
+			_, err := runOverexported(t, "-C", "testdata/foo", "--build-flag=-bogus", "./...")
+			require.ErrorContains(t, err, "flag provided but not defined: -bogus")
+		})
+	})
+
+	t.Run("modfile build flag", func(t *testing.T) {
+		t.Parallel()
+		// go.mod names a different module, so the main module has to come
+		// from alt.mod too.
+		stdout, err := runOverexported(t, "-C", "testdata/modfileflag", "--json", "--build-flag=-modfile=alt.mod", "./...")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Unused"}, exportNames(parseJSONOutput(t, stdout)))
+	})
+
+	t.Run("build config", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/buildtags", "--json-envelope", "--tags=prod", "./...")
+		require.NoError(t, err)
+		var env jsonEnvelope
+		require.NoError(t, json.Unmarshal([]byte(stdout), &env))
+		require.NotNil(t, env.Meta.BuildConfig)
+		assert.NotEmpty(t, env.Meta.BuildConfig.GOOS)
+		assert.NotEmpty(t, env.Meta.BuildConfig.GOARCH)
+		assert.Equal(t, []string{"prod"}, env.Meta.BuildConfig.Tags)
+
+		// Tags set with --build-flag are what the go command loaded with too.
+		stdout, err = runOverexported(t, "-C", "testdata/buildtags", "--json-envelope", "--build-flag=-tags=prod", "./...")
+		require.NoError(t, err)
+		env = jsonEnvelope{}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &env))
+		require.NotNil(t, env.Meta.BuildConfig)
+		assert.Equal(t, []string{"prod"}, env.Meta.BuildConfig.Tags)
+	})
+
+	t.Run("packages with the same name", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/samename", "--json", "--suggest-dedup", "./...")
+		require.NoError(t, err)
+		var got []string
+		for _, exp := range parseJSONOutput(t, stdout) {
+			got = append(got, exp.PkgPath+"."+exp.Name)
+			assert.Empty(t, exp.PossibleDuplicates, "duplicates are only suggested within a package")
+		}
+		assert.Equal(t, []string{"samename/a/util.Helper", "samename/b/util.Helper"}, got)
+
+		stdout, err = runOverexported(t, "-C", "testdata/samename", "./...")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "\nsamename/a/util:\n")
+		assert.Contains(t, stdout, "\nsamename/b/util:\n")
+		assert.NotContains(t, stdout, "\nutil:\n")
+
+		keepFile := filepath.Join(t.TempDir(), "keep")
+		require.NoError(t, os.WriteFile(keepFile, []byte("samename/a/util.Helper\n"), 0o600))
+		stdout, err = runOverexported(t, "-C", "testdata/samename", "--json", "--keep-file", keepFile, "./...")
+		require.NoError(t, err)
+		exports := parseJSONOutput(t, stdout)
+		require.Len(t, exports, 1)
+		assert.Equal(t, "samename/b/util", exports[0].PkgPath)
+	})
+
+	t.Run("called methods report", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/calledmethods", "--json-envelope", "--report=called-methods", "./...")
+		require.NoError(t, err)
+		var env jsonEnvelope
+		require.NoError(t, json.Unmarshal([]byte(stdout), &env))
+		assert.Equal(t, []overexported.CalledMethods{{
+			Type:             "calledmethods/client.Client",
+			Methods:          []string{"Close", "Get", "Put"},
+			CalledByExternal: []string{"Get"},
+		}}, env.CalledMethods)
+
+		stdout, err = runOverexported(t, "-C", "testdata/calledmethods", "--report=called-methods", "./...")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "\nMethods used by other packages of types taken as parameters:\n  calledmethods/client.Client: Get (1 of 3)\n")
+	})
+
+	t.Run("include all tags", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/featuretags", "--json", "./...")
+		require.NoError(t, err)
+		assert.NotContains(t, exportNames(parseJSONOutput(t, stdout)), "Experimental")
+
+		stdout, err = runOverexported(t, "-C", "testdata/featuretags", "--json-envelope", "--include-all-tags", "./...")
+		require.NoError(t, err)
+		var env jsonEnvelope
+		require.NoError(t, json.Unmarshal([]byte(stdout), &env))
+		names := exportNames(env.Exports)
+		assert.Contains(t, names, "Experimental")
+		assert.Contains(t, names, "Unused")
+		assert.NotContains(t, names, "OtherOS")
+		require.NotNil(t, env.Meta.BuildConfig)
+		// legacy is only forbidden and plan9 is a GOOS, so neither is set.
+		assert.Equal(t, []string{"experimental"}, env.Meta.BuildConfig.Tags)
+	})
+
+	t.Run("inventory report", func(t *testing.T) {
+		t.Parallel()
+		keepFile := filepath.Join(t.TempDir(), "keep")
+		require.NoError(t, os.WriteFile(keepFile, []byte("generated.ManualUnused\n"), 0o600))
+		stdout, err := runOverexported(t, "-C", "testdata/generated", "--json-envelope", "--report=inventory", "--keep-file", keepFile, "./...")
+		require.NoError(t, err)
+		var env jsonEnvelope
+		require.NoError(t, json.Unmarshal([]byte(stdout), &env))
+		assert.Empty(t, env.Exports)
+		statuses := make(map[string]string)
+		for _, entry := range env.Inventory {
+			statuses[entry.Name] = entry.Status
+		}
+		assert.Equal(t, map[string]string{
+			"GeneratedUnused": "kept-generated",
+			"GeneratedUsed":   "used",
+			"ManualUnused":    "suppressed",
+			"ManualUsed":      "used",
+		}, statuses)
+		assert.Equal(t, []string{"generated/cmd"}, env.Inventory[1].UsedBy)
+		// Confidence is only computed for reported exports.
+		assert.NotContains(t, stdout, `"confidence"`)
+	})
+
+	t.Run("interface map report", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/ifacemap", "--json-envelope", "--report=interface-map", "./...")
+		require.NoError(t, err)
+		var env jsonEnvelope
+		require.NoError(t, json.Unmarshal([]byte(stdout), &env))
+		assert.Equal(t, map[string][]string{
+			"ifacemap/lib.Buffer": {"fmt.Stringer", "ifacemap/lib.Flusher", "io.Writer"},
+		}, env.InterfaceMap)
+	})
+
+	t.Run("orphan packages report", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("envelope", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/orphans", "--json-envelope", "--report=orphan-packages", "./...")
+			require.NoError(t, err)
+			var env jsonEnvelope
+			require.NoError(t, json.Unmarshal([]byte(stdout), &env))
+			assert.Equal(t, []string{"orphans/orphan"}, env.OrphanPackages)
+		})
+
+		t.Run("imported by tests", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/orphans", "--test", "--report=orphan-packages", "./...")
+			require.NoError(t, err)
+			assert.NotContains(t, stdout, "Packages not imported")
+		})
+	})
+
+	t.Run("ignored files", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/ignoredfile", "--json", "./...")
+		require.NoError(t, err)
+		flagged := make(map[string]bool)
+		for _, exp := range parseJSONOutput(t, stdout) {
+			flagged[exp.Name] = exp.MaybeUsedByIgnoredFile
+		}
+		assert.Equal(t, map[string]bool{"Names": true, "Unused": false, "Marshal": true}, flagged)
+	})
+
+	t.Run("packages only", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("text", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/reldir", "--packages-only", "./...")
+			require.NoError(t, err)
+			assert.Equal(t, "reldir/a\nreldir/b\n", stdout)
+		})
+
+		t.Run("json", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/reldir", "--packages-only", "--json", "./...")
+			require.NoError(t, err)
+			var pkgs []string
+			require.NoError(t, json.Unmarshal([]byte(stdout), &pkgs))
+			assert.Equal(t, []string{"reldir/a", "reldir/b"}, pkgs)
+		})
+
+		t.Run("no findings", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/reldir", "--packages-only", "--json", "./cmd")
+			require.NoError(t, err)
+			assert.Equal(t, "[]\n", stdout)
+		})
+	})
+
+	t.Run("methods sharing a package-level name", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/nameclash", "--json", "./...")
+		require.NoError(t, err)
+		for _, exp := range parseJSONOutput(t, stdout) {
+			assert.False(t, exp.SharesTopLevelName, exp.Name)
+		}
+
+		stdout, err = runOverexported(t, "-C", "testdata/nameclash", "--json", "--report=name-clashes", "./...")
+		require.NoError(t, err)
+		flagged := make(map[string]bool)
+		for _, exp := range parseJSONOutput(t, stdout) {
+			flagged[exp.Name] = exp.SharesTopLevelName
+		}
+		assert.Equal(t, map[string]bool{"Store.Get": true, "Store.Put": false}, flagged)
+	})
+
+	t.Run("undocumented only", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/undocumented", "--json", "--undocumented-only", "./...")
+		require.NoError(t, err)
+		names := exportNames(parseJSONOutput(t, stdout))
+		assert.ElementsMatch(t, []string{"Undocumented", "DocumentedType.UndocumentedMethod", "UndocumentedConst"}, names)
+	})
+
+	t.Run("used only by", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/usedonlyby", "--json-envelope", "--used-only-by=usedonlyby/legacy/...", "./...")
+		require.NoError(t, err)
+		var env jsonEnvelope
+		require.NoError(t, json.Unmarshal([]byte(stdout), &env))
+		assert.Equal(t, []string{"Unused"}, exportNames(env.Exports))
+		require.Len(t, env.UsedOnlyBy, 1)
+		assert.Equal(t, "LegacyOnly", env.UsedOnlyBy[0].Name)
+		assert.Equal(t, []string{"usedonlyby/legacy/old", "usedonlyby/legacy/older"}, env.UsedOnlyBy[0].UsedBy)
+
+		stdout, err = runOverexported(t, "-C", "testdata/usedonlyby", "--used-only-by=usedonlyby/legacy/old", "--used-only-by=usedonlyby/app", "./...")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "Exports used only by --used-only-by packages:\n"+
+			"  usedonlyby/lib.AppOnly (func): used by usedonlyby/app\n"+
+			"  usedonlyby/lib.Shared (func): used by usedonlyby/app, usedonlyby/legacy/old\n")
+	})
+
+	t.Run("pending deletion", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/pendingdeletion", "--json", "./...")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"Unused", "Helper"}, exportNames(parseJSONOutput(t, stdout)))
+
+		manifest, err := filepath.Abs("testdata/pendingdeletion/pending-deletion.txt")
+		require.NoError(t, err)
+		stdout, err = runOverexported(t, "-C", "testdata/pendingdeletion", "--json", "--pending-deletion", manifest, "./...")
+		require.NoError(t, err)
+		orphaned := make(map[string]bool)
+		for _, exp := range parseJSONOutput(t, stdout) {
+			orphaned[exp.Name] = exp.WillBeOrphaned
+		}
+		assert.Equal(t, map[string]bool{"LegacyOnly": true, "Unused": false}, orphaned)
+
+		stdout, err = runOverexported(t, "-C", "testdata/pendingdeletion", "--pending-deletion", manifest, "./...")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "LegacyOnly (func) ./testdata/pendingdeletion/lib/lib.go:4 [only used by packages pending deletion]")
+	})
+
+	t.Run("ignore deprecated", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/deprecated", "--json", "./...")
+		require.NoError(t, err)
+		names := exportNames(parseJSONOutput(t, stdout))
+		assert.ElementsMatch(t, []string{"Old", "New", "Mentioned", "OldLimit", "Limit", "T.OldMethod"}, names)
+
+		stdout, err = runOverexported(t, "-C", "testdata/deprecated", "--json", "--ignore-deprecated", "./...")
+		require.NoError(t, err)
+		names = exportNames(parseJSONOutput(t, stdout))
+		assert.ElementsMatch(t, []string{"New", "Mentioned", "Limit"}, names)
+	})
+
+	t.Run("interfaces without external implementers", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/ifaceimpl", "--json", "./...")
+		require.NoError(t, err)
+		for _, exp := range parseJSONOutput(t, stdout) {
+			assert.False(t, exp.NoExternalImplementers, exp.Name)
+		}
+
+		stdout, err = runOverexported(t, "-C", "testdata/ifaceimpl", "--json", "--report=no-external-implementers", "./...")
+		require.NoError(t, err)
+		flagged := make(map[string]bool)
+		for _, exp := range parseJSONOutput(t, stdout) {
+			flagged[exp.Name] = exp.NoExternalImplementers
+		}
+		assert.Equal(t, map[string]bool{"InternalOnly": true, "Plugin": false}, flagged)
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("with results", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/foo", "--markdown", "--test", "./...")
+			require.NoError(t, err)
+			assert.Equal(t, "## Over-exported identifiers (1)\n\n"+
+				"### `baz/foo`\n\n"+
+				"| Identifier | Kind | Location |\n"+
+				"| --- | --- | --- |\n"+
+				"| `Bar` | func | [cmd/overexported/testdata/foo/foo.go:7](cmd/overexported/testdata/foo/foo.go#L7) |\n", stdout)
+		})
+
+		t.Run("empty results", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/foo", "--markdown", "baz/foo/cmd/foo")
+			require.NoError(t, err)
+			assert.Equal(t, "## Over-exported identifiers (0)\n\nNo over-exported identifiers found.\n", stdout)
+		})
+
+		t.Run("links relative to the -C repository", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", writeOtherRepo(t), "--markdown", "./...")
+			require.NoError(t, err)
+			assert.Contains(t, stdout, "| `Unused` | func | [lib/lib.go:3](lib/lib.go#L3) |\n")
+		})
+
+		t.Run("not compatible with json", func(t *testing.T) {
+			t.Parallel()
+			_, err := runOverexported(t, "-C", "testdata/foo", "--markdown", "--json", "./...")
+			require.EqualError(t, err, "--json and --markdown can't be used together")
+		})
+	})
+
+	t.Run("sarif", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("with results", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/foo", "--sarif", "--test", "./...")
+			require.NoError(t, err)
+			var log struct {
+				Version string `json:"version"`
+				Runs    []struct {
+					Tool struct {
+						Driver struct {
+							Rules []struct {
+								ID string `json:"id"`
+							} `json:"rules"`
+						} `json:"driver"`
+					} `json:"tool"`
+					Results []struct {
+						RuleID  string `json:"ruleId"`
+						Message struct {
+							Text string `json:"text"`
+						} `json:"message"`
+						Locations []struct {
+							PhysicalLocation struct {
+								ArtifactLocation struct {
+									URI string `json:"uri"`
+								} `json:"artifactLocation"`
+								Region struct {
+									StartLine   int `json:"startLine"`
+									StartColumn int `json:"startColumn"`
+								} `json:"region"`
+							} `json:"physicalLocation"`
+						} `json:"locations"`
+					} `json:"results"`
+				} `json:"runs"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(stdout), &log))
+			assert.Equal(t, "2.1.0", log.Version)
+			require.Len(t, log.Runs, 1)
+			run := log.Runs[0]
+			assert.Len(t, run.Tool.Driver.Rules, 5)
+			require.Len(t, run.Results, 1)
+			result := run.Results[0]
+			assert.Equal(t, "overexported/func", result.RuleID)
+			assert.Equal(t, "Bar (func) can be unexported", result.Message.Text)
+			require.Len(t, result.Locations, 1)
+			loc := result.Locations[0].PhysicalLocation
+			assert.Equal(t, "cmd/overexported/testdata/foo/foo.go", loc.ArtifactLocation.URI)
+			assert.Equal(t, 7, loc.Region.StartLine)
+			assert.Equal(t, 6, loc.Region.StartColumn)
+		})
+
+		t.Run("uris relative to the -C repository", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", writeOtherRepo(t), "--sarif", "./...")
+			require.NoError(t, err)
+			assert.Contains(t, stdout, `"uri": "lib/lib.go"`)
+		})
+
+		t.Run("not compatible with json", func(t *testing.T) {
+			t.Parallel()
+			_, err := runOverexported(t, "-C", "testdata/foo", "--sarif", "--json", "./...")
+			require.EqualError(t, err, "--json and --sarif can't be used together")
+		})
+	})
+
+	t.Run("hint regex", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("matches method names", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/types", "--json", "--hint-regex=^Unused.*Method$", "./...")
+			require.NoError(t, err)
+			hints := make(map[string]bool)
+			for _, exp := range parseJSONOutput(t, stdout) {
+				hints[exp.Name] = exp.NameHint
+			}
+			assert.Equal(t, map[string]bool{
+				"UnusedType":                  false,
+				"UnusedType.UnusedTypeMethod": true,
+				"UsedType.UnusedMethod":       true,
+			}, hints)
+		})
+
+		t.Run("invalid pattern", func(t *testing.T) {
+			t.Parallel()
+			_, err := runOverexported(t, "-C", "testdata/types", "--hint-regex=(", "./...")
+			require.ErrorContains(t, err, "invalid name hint pattern")
+		})
+	})
+
+	t.Run("only", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("named identifiers", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/types", "--json", "--only=types.UnusedType,types.UsedType", "./...")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"UnusedType"}, exportNames(parseJSONOutput(t, stdout)))
+		})
+
+		t.Run("unknown identifier", func(t *testing.T) {
+			t.Parallel()
+			_, err := runOverexported(t, "-C", "testdata/types", "--only=types.UnusedType,types.Nope", "./...")
+			require.EqualError(t, err, "exported identifiers not found: types.Nope")
+		})
+	})
+
+	t.Run("no headers", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("text", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/constvars", "--no-headers", "--test", "--sort-by=position", "./...")
+			require.NoError(t, err)
+			assert.Equal(t, "testdata/constvars/constvars.go:7:7: UnusedConst (const)\n"+
+				"testdata/constvars/constvars.go:13:5: UnusedVar (var)\n"+
+				"testdata/constvars/constvars.go:21:6: UnusedFunc (func)\n", stdout)
+		})
+
+		t.Run("not compatible with json", func(t *testing.T) {
+			t.Parallel()
+			_, err := runOverexported(t, "-C", "testdata/constvars", "--no-headers", "--json", "./...")
+			require.EqualError(t, err, "--json and --no-headers can't be used together")
+		})
+	})
+
+	t.Run("internal strict", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/internalstrict", "--json", "--internal-strict", "./...")
+		require.NoError(t, err)
+		flagged := make(map[string]bool)
+		for _, exp := range parseJSONOutput(t, stdout) {
+			flagged[exp.Name] = exp.InternalStrict
+		}
+		assert.Equal(t, map[string]bool{"TestOnly": true, "Unused": false}, flagged)
+	})
+
+	t.Run("enum groups", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/enums", "--json", "./...")
+		require.NoError(t, err)
+		for _, exp := range parseJSONOutput(t, stdout) {
+			assert.Empty(t, exp.EnumGroup, exp.Name)
+		}
+
+		stdout, err = runOverexported(t, "-C", "testdata/enums", "--json", "--report=enum-groups", "./...")
+		require.NoError(t, err)
+		groups := make(map[string]string)
+		for _, exp := range parseJSONOutput(t, stdout) {
+			if exp.Kind == "const" {
+				groups[exp.Name] = exp.EnumGroup
+			}
+		}
+		assert.Equal(t, map[string]string{"ColorPurple": "Color", "OnlyLonely": "", "Untyped": ""}, groups)
+	})
+
+	t.Run("dead param types", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/deadparams", "--json", "./...")
+		require.NoError(t, err)
+		for _, exp := range parseJSONOutput(t, stdout) {
+			assert.Empty(t, exp.DeadParamTypes, exp.Name)
+		}
+
+		stdout, err = runOverexported(t, "-C", "testdata/deadparams", "--json", "--report=unused-params", "./...")
+		require.NoError(t, err)
+		deadParams := make(map[string][]string)
+		for _, exp := range parseJSONOutput(t, stdout) {
+			if exp.Kind != "type" {
+				deadParams[exp.Name] = exp.DeadParamTypes
+			}
+		}
+		assert.Equal(t, map[string][]string{
+			"New":        {"deadparams/lib.Config", "deadparams/lib.Option"},
+			"Plain":      {"deadparams/lib.Used"},
+			"Used.Apply": {"deadparams/lib.Option"},
+		}, deadParams)
+	})
+
+	t.Run("rename suggestions", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/renames", "--json", "--experimental-rename-suggestions", "./...")
+		require.NoError(t, err)
+		suggested := make(map[string]string)
+		for _, exp := range parseJSONOutput(t, stdout) {
+			suggested[exp.Name] = exp.SuggestedName
+		}
+		for _, tc := range []struct {
+			name string
+			want string
+		}{
+			{name: "URL", want: "url"},
+			{name: "ID", want: "id"},
+			{name: "API", want: "api"},
+			{name: "OAuth2Token", want: "oauth2Token"},
+			{name: "HTTPClient", want: "httpClient"},
+			{name: "IDsByName", want: "idsByName"},
+			{name: "FOOBar", want: "fooBar"},
+			{name: "IDE", want: "ide"},
+			{name: "IPv4", want: "ipv4"},
+			{name: "IOStream", want: "ioStream"},
+			{name: "Type", want: ""},
+			{name: "Used.JSONValue", want: "jsonValue"},
+			{name: "Used.String", want: "string"},
+			{name: "Person.Name", want: ""},
+			{name: "Person.Age", want: "age"},
+			{name: "Person.Describe", want: ""},
+			{name: "New", want: ""},
+			{name: "String", want: ""},
+			{name: "Clash", want: ""},
+		} {
+			got, ok := suggested[tc.name]
+			if assert.True(t, ok, "%s not reported", tc.name) {
+				assert.Equal(t, tc.want, got, tc.name)
+			}
+		}
+	})
+
+	t.Run("debug reasons", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/internalstrict", "--json", "--internal-strict", "--debug-reasons", "./...")
+		require.NoError(t, err)
+		reasons := make(map[string][]string)
+		for _, exp := range parseJSONOutput(t, stdout) {
+			reasons[exp.Name] = exp.Reasons
+		}
+		assert.Equal(t, map[string][]string{
+			"TestOnly": {"external call from internalstrict/other", "no type ref", "external reference from internalstrict/other"},
+			"Unused":   {"no external call", "no type ref", "no external reference"},
+		}, reasons)
+	})
+
+	t.Run("transitive", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("without --transitive", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/transitive", "--json", "./...")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Baz"}, exportNames(parseJSONOutput(t, stdout)))
+		})
+
+		t.Run("with --transitive", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/transitive", "--json", "--transitive", "./...")
+			require.NoError(t, err)
+			usedBy := make(map[string][]string)
+			for _, exp := range parseJSONOutput(t, stdout) {
+				usedBy[exp.Name] = exp.OnlyUsedByUnreachable
+			}
+			assert.Equal(t, map[string][]string{
+				"Bar":     {"transitive/pkgb.Baz"},
+				"BarType": {"transitive/pkgb.Baz"},
+				"Baz":     nil,
+			}, usedBy)
+		})
+
+		t.Run("with --ignore-dead-callers", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/transitive", "--json", "--ignore-dead-callers", "./...")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Bar", "BarType", "Baz"}, exportNames(parseJSONOutput(t, stdout)))
+		})
+	})
+
+	t.Run("collapse methods", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/types", "--json", "--test", "--collapse-methods", "./...")
+		require.NoError(t, err)
+		names := exportNames(parseJSONOutput(t, stdout))
+		assert.Contains(t, names, "UnusedType")
+		assert.Contains(t, names, "UsedType.UnusedMethod")
+		assert.NotContains(t, names, "UnusedType.UnusedTypeMethod")
+	})
+
+	t.Run("suggest dedup", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/dedup", "--json", "--suggest-dedup", "./...")
+		require.NoError(t, err)
+		exports := parseJSONOutput(t, stdout)
+		dups := make(map[string][]string)
+		for _, exp := range exports {
+			dups[exp.Name] = exp.PossibleDuplicates
+		}
+		assert.Equal(t, map[string][]string{
+			"ParseDecimal": {"ParseNumber"},
+			"ParseNumber":  {"ParseDecimal"},
+			"Format":       nil,
+		}, dups)
+	})
+
+	t.Run("format", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("custom template", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/constvars", "--test", "-f", "{{.PkgPath}}.{{.Name}} {{.Kind}}", "./...")
+			require.NoError(t, err)
+			assert.Equal(t, "constvars.UnusedConst const\nconstvars.UnusedFunc func\nconstvars.UnusedVar var\n", stdout)
+		})
+
+		t.Run("github-actions preset", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/foo", "--test", "--preset=github-actions", "./...")
+			require.NoError(t, err)
+			assert.Equal(t, "::warning file=testdata/foo/foo.go,line=7,col=6::Bar (func) can be unexported\n", stdout)
+		})
+
+		t.Run("format overrides preset", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/foo", "--test", "--preset=github-actions", "-f", "{{.Name}}", "./...")
+			require.NoError(t, err)
+			assert.Equal(t, "Bar\n", stdout)
+		})
+
+		t.Run("invalid template returns error", func(t *testing.T) {
+			t.Parallel()
+			_, err := runOverexported(t, "-C", "testdata/foo", "-f", "{{.Name", "./...")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid format")
+		})
+
+		t.Run("json and format are mutually exclusive", func(t *testing.T) {
+			t.Parallel()
+			_, err := runOverexported(t, "-C", "testdata/foo", "--json", "-f", "{{.Name}}", "./...")
+			require.Error(t, err)
+		})
+	})
+
+	t.Run("text output", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("with results", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/foo", "--test", "./...")
+			require.NoError(t, err)
+
+			assert.Contains(t, stdout, "baz/foo:")
+			assert.Contains(t, stdout, "Bar")
+			assert.Contains(t, stdout, "func")
+		})
+
+		t.Run("empty results", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/foo", "--test", "baz/foo/cmd/foo")
+			require.NoError(t, err)
+			assert.Contains(t, stdout, "No over-exported identifiers found")
+		})
+	})
+
+	t.Run("json flat position", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/types", "--json", "--json-flat-position", "./...")
+		require.NoError(t, err)
+		var records []map[string]any
+		require.NoError(t, json.Unmarshal([]byte(stdout), &records))
+		require.NotEmpty(t, records)
+		assert.Equal(t, "UnusedType", records[0]["name"])
+		wantPosition, err := filepath.Abs("testdata/types/types.go")
+		require.NoError(t, err)
+		assert.Equal(t, wantPosition+":19:6", records[0]["position"])
+
+		_, err = runOverexported(t, "-C", "testdata/types", "--json-flat-position", "./...")
+		require.Error(t, err)
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("text", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/types", "--stats", "./...")
+			require.NoError(t, err)
+			assert.Equal(t, strings.Join([]string{
+				"PACKAGE  EXPORTED  USED  OVER-EXPORTED  UTILIZATION",
+				"types    5         2     3              40.0%",
+				"TOTAL    5         2     3              40.0%",
+				"",
+			}, "\n"), stdout)
+		})
+
+		t.Run("json", func(t *testing.T) {
+			t.Parallel()
+			stdout, err := runOverexported(t, "-C", "testdata/foo", "--test", "--stats", "--json", "./...")
+			require.NoError(t, err)
+			var got statsOutput
+			require.NoError(t, json.Unmarshal([]byte(stdout), &got))
+			want := exportStats{Exported: 2, Used: 1, OverExported: 1, Utilization: 50}
+			assert.Equal(t, want, got.Total)
+			want.PkgPath = "baz/foo"
+			assert.Equal(t, []exportStats{want}, got.Packages)
+		})
+
+		t.Run("not compatible with filters", func(t *testing.T) {
+			t.Parallel()
+			baseline := filepath.Join(t.TempDir(), "baseline.json")
+			require.NoError(t, os.WriteFile(baseline, []byte("[]\n"), 0o600))
+			for _, args := range [][]string{{"--check", baseline}, {"--staged"}} {
+				_, err := runOverexported(t, append([]string{"-C", "testdata/foo", "--stats"}, append(args, "./...")...)...)
+				require.EqualError(t, err, "--stats is not compatible with --check or --staged")
+			}
+		})
+	})
+
+	t.Run("check", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "-C", "testdata/types", "--json", "./...")
+		require.NoError(t, err)
+		exports := parseJSONOutput(t, stdout)
+		require.Contains(t, exportNames(exports), "UnusedType")
+
+		t.Run("no regressions", func(t *testing.T) {
+			t.Parallel()
+			baseline := filepath.Join(t.TempDir(), "baseline.json")
+			require.NoError(t, os.WriteFile(baseline, []byte(stdout), 0o600))
+			got, err := runOverexported(t, "-C", "testdata/types", "--check", baseline, "./...")
+			require.NoError(t, err)
+			assert.Equal(t, "No over-exported identifiers found.\n", got)
+		})
+
+		t.Run("regressions and improvements", func(t *testing.T) {
+			t.Parallel()
+			old := slices.DeleteFunc(slices.Clone(exports), func(exp overexported.Export) bool {
+				return exp.Name == "UnusedType"
+			})
+			old = append(old, overexported.Export{Name: "Removed", Kind: "func", PkgPath: "types"})
+			data, err := json.Marshal(old)
+			require.NoError(t, err)
+			baseline := filepath.Join(t.TempDir(), "baseline.json")
+			require.NoError(t, os.WriteFile(baseline, data, 0o600))
+			var buf bytes.Buffer
+			err = run(&buf, []string{"-C", "testdata/types", "--check", baseline, "./..."})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "found 1 over-exported identifiers not in")
+			got := buf.String()
+			assert.Contains(t, got, "UnusedType (type)")
+			assert.NotContains(t, got, "UnusedType.UnusedTypeMethod")
+			assert.Contains(t, got, "In the baseline but no longer reported:\n  types.Removed (func)\n")
+		})
+	})
+
+	t.Run("out", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("multiple destinations", func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			jsonFile := filepath.Join(dir, "report.json")
+			mdFile := filepath.Join(dir, "report.md")
+			stdout, err := runOverexported(t, "-C", "testdata/foo", "--test", "--out=text:-", "--out=json:"+jsonFile, "--out=markdown:"+mdFile, "./...")
+			require.NoError(t, err)
+			assert.Contains(t, stdout, "baz/foo:")
+			data, err := os.ReadFile(jsonFile)
+			require.NoError(t, err)
+			assert.Contains(t, exportNames(parseJSONOutput(t, string(data))), "Bar")
+			data, err = os.ReadFile(mdFile)
+			require.NoError(t, err)
+			assert.Contains(t, string(data), "| `Bar` |")
+		})
+
+		t.Run("unknown format", func(t *testing.T) {
+			t.Parallel()
+			_, err := runOverexported(t, "-C", "testdata/foo", "--out=xml:results.xml", "./...")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `unknown format "xml"`)
+		})
+
+		t.Run("not compatible with other output modes", func(t *testing.T) {
+			t.Parallel()
+			_, err := runOverexported(t, "-C", "testdata/foo", "--json", "--out=text:-", "./...")
+			require.EqualError(t, err, "--json and --out can't be used together")
+			_, err = runOverexported(t, "-C", "testdata/foo", "--stats", "--out=text:-", "./...")
+			require.EqualError(t, err, "--stats and --out can't be used together")
+		})
+	})
+}
+
+// Test_run_workspace isn't parallel because it needs to clear GOFLAGS.
+// Workspace mode rejects -mod=mod, which some environments set by default.
+// Test_confidence checks that each risk signal lowers the confidence score
+// of the exports that carry it, against exports of the same kind that don't.
+func Test_confidence(t *testing.T) {
+	t.Parallel()
+	pendingDeletion, err := filepath.Abs("testdata/pendingdeletion/pending-deletion.txt")
+	require.NoError(t, err)
+	for _, tt := range []struct {
+		name string
+		dir  string
+		args []string
+		want map[string]float64
+	}{
+		{
+			name: "kinds",
+			dir:  "testdata/renames",
+			want: map[string]float64{"Clash": 1, "URL": 1, "ID": 0.9, "API": 0.8, "Person.Age": 0.6},
+		},
+		{
+			name: "unexported receiver",
+			dir:  "testdata/receivers",
+			args: []string{"--test", "--unexported-receivers"},
+			want: map[string]float64{"internal.Export": 0.9},
+		},
+		{
+			name: "dynamically checked method name",
+			dir:  "testdata/renames",
+			want: map[string]float64{"Used.JSONValue": 0.6, "Used.String": 0.3},
+		},
+		{
+			name: "template access",
+			dir:  "testdata/templates",
+			want: map[string]float64{"NotRendered.Heading": 0.6, "Page.Footer": 0.3},
+		},
+		{
+			name: "ignored file",
+			dir:  "testdata/ignoredfile",
+			want: map[string]float64{"Unused": 1, "Marshal": 0.5, "Names": 0.45},
+		},
+		{
+			name: "generated callers",
+			dir:  "testdata/genregistry",
+			args: []string{"--ignore-generated-callers"},
+			want: map[string]float64{"Unregistered": 1, "CalledFromGenerated": 0.5},
+		},
+		{
+			name: "unreachable users",
+			dir:  "testdata/transitive",
+			args: []string{"--transitive"},
+			want: map[string]float64{"Baz": 1, "Bar": 0.8, "BarType": 0.64},
+		},
+		{
+			name: "examples",
+			dir:  "testdata/examplespkg",
+			args: []string{"--report=examples-pkg"},
+			want: map[string]float64{"Unused": 1, "ExampleOnly": 0.7},
+		},
+		{
+			name: "mocks",
+			dir:  "testdata/mockonly",
+			args: []string{"--report=mock-only"},
+			want: map[string]float64{"Store": 0.56},
+		},
+		{
+			name: "pending deletion",
+			dir:  "testdata/pendingdeletion",
+			args: []string{"--pending-deletion", pendingDeletion},
+			want: map[string]float64{"Unused": 1, "LegacyOnly": 0.7},
+		},
+		{
+			name: "test coverage gap",
+			dir:  "testdata/external_test",
+			args: []string{"--strict-test"},
+			want: map[string]float64{"NotUsedInTests": 1, "OnlyUsedInTests": 0.7},
+		},
+		{
+			name: "internal strict",
+			dir:  "testdata/internalstrict",
+			args: []string{"--internal-strict"},
+			want: map[string]float64{"Unused": 1, "TestOnly": 0.7},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			args := append([]string{"-C", tt.dir, "--json"}, tt.args...)
+			stdout, err := runOverexported(t, append(args, "./...")...)
+			require.NoError(t, err)
+			got := make(map[string]float64)
+			for _, exp := range parseJSONOutput(t, stdout) {
+				if _, ok := tt.want[exp.Name]; ok {
+					got[exp.Name] = exp.Confidence
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_run_workspace(t *testing.T) {
+	t.Setenv("GOFLAGS", "")
+
+	tests := []struct {
+		name string
+		dir  string
+		args []string
+	}{
+		{name: "module with no main", dir: "testdata/workspace/moda", args: []string{"./..."}},
+		{name: "import path from another module", dir: "testdata/workspace/modb", args: []string{"moda"}},
+		{name: "workspace root", dir: "testdata/workspace", args: []string{"moda/...", "modb/..."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"-C", tt.dir, "--json"}, tt.args...)
+			stdout, err := runOverexported(t, args...)
+			require.NoError(t, err)
+			names := exportNames(parseJSONOutput(t, stdout))
+			assert.Contains(t, names, "Unused")
+			assert.NotContains(t, names, "CrossModuleUsed")
+		})
+	}
+}
+
+// Test_run_gopathVendor isn't parallel because it switches to GOPATH mode,
+// the only mode where packages are loaded from nested vendor directories
+// under their vendored path.
+func Test_run_gopathVendor(t *testing.T) {
+	gopath, err := filepath.Abs("testdata/gopathvendor")
+	require.NoError(t, err)
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GO111MODULE", "off")
+	t.Setenv("GOPATH", gopath)
+
+	stdout, err := runOverexported(t, "-C", "testdata/gopathvendor/src/vend", "--json", "./...")
+	require.NoError(t, err)
+	names := exportNames(parseJSONOutput(t, stdout))
+	assert.Contains(t, names, "Unused")
+	assert.NotContains(t, names, "Foo")
+}
+
+// Test_run_deepTypes checks that a type nested hundreds of levels deep is
+// walked to the end and doesn't hang the analysis. go/parser limits nesting
+// well below the depth that would exhaust the stack of a recursive walk, so
+// this checks termination and correctness rather than stack use.
+func Test_run_deepTypes(t *testing.T) {
+	const depth = 500
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "lib"), 0o700))
+	files := map[string]string{
+		"go.mod": "module deeptypes\n\ngo 1.25.1\n",
+		// v's type is never named in main, so Leaf is only found by walking
+		// the type of the allocation.
+		"main.go": "package main\n\nimport \"deeptypes/lib\"\n\nvar sink any\n\nfunc main() {\n\tv := lib.Make()\n\tsink = &v\n}\n",
+		"lib/lib.go": "package lib\n\ntype Leaf struct{}\n\ntype Unused struct{}\n\nfunc Make() " +
+			strings.Repeat("[]", depth) + "Leaf {\n\treturn nil\n}\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	stdout, err := runOverexported(t, "-C", dir, "--json", "./...")
+	require.NoError(t, err)
+	names := exportNames(parseJSONOutput(t, stdout))
+	assert.Contains(t, names, "Unused")
+	assert.NotContains(t, names, "Leaf")
+	assert.NotContains(t, names, "Make")
+}
+
+func Test_sourceDateEpochNow(t *testing.T) {
+	t.Parallel()
+
+	t.Run("set", func(t *testing.T) {
+		t.Parallel()
+		now := sourceDateEpochNow(func(string) string { return "1700000000" })
+		require.NotNil(t, now)
+		assert.Equal(t, time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC), now())
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		t.Parallel()
+		assert.Nil(t, sourceDateEpochNow(func(string) string { return "" }))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		assert.Nil(t, sourceDateEpochNow(func(string) string { return "yesterday" }))
+	})
+}
+
+func Test_runSelfcheck(t *testing.T) {
+	t.Parallel()
+	_, err := runOverexported(t, "selfcheck")
+	require.NoError(t, err)
+
+	_, err = runOverexported(t, "selfcheck", "-C", "../../internal")
+	require.NoError(t, err)
+
+	other, err := filepath.Abs("testdata/foo")
+	require.NoError(t, err)
+	_, err = runOverexported(t, "selfcheck", "-C", other)
+	require.EqualError(t, err, "selfcheck: "+other+" is not in a checkout of github.com/willabides/overexported")
+}
+
+func Test_run_staged(t *testing.T) {
+	t.Parallel()
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, os.CopyFS(dir, os.DirFS("testdata/reldir")))
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	// These change the paths in "git diff" output, which --staged reads.
+	git("config", "diff.noprefix", "true")
+	git("config", "diff.mnemonicPrefix", "true")
+	git("config", "core.quotePath", "true")
+	baseline, err := runOverexported(t, "-C", dir, "--json", "./...")
+	require.NoError(t, err)
+	baselineFile := filepath.Join(t.TempDir(), "baseline.json")
+	require.NoError(t, os.WriteFile(baselineFile, []byte(baseline), 0o600))
+
+	f, err := os.OpenFile(filepath.Join(dir, "b", "b.go"), os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString("\n// BStaged is new.\nfunc BStaged() {}\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	git("add", "b/b.go")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b", "new file.go"), []byte("package b\n\n// BNew is new.\nfunc BNew() {}\n"), 0o600))
+	git("add", "b/new file.go")
+
+	stdout, err := runOverexported(t, "-C", dir, "--json", "--staged", "./...")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"BStaged", "BNew"}, exportNames(parseJSONOutput(t, stdout)))
+
+	// Baseline exports on unstaged lines are still reported, not fixed.
+	var buf bytes.Buffer
+	err = run(&buf, []string{"-C", dir, "--check", baselineFile, "--staged", "./..."})
+	require.EqualError(t, err, "found 2 over-exported identifiers not in "+baselineFile)
+	assert.Contains(t, buf.String(), "BStaged")
+	assert.NotContains(t, buf.String(), "no longer reported")
+}
+
+func Test_parseHunkHeader(t *testing.T) {
+	t.Parallel()
+	for _, tt := range []struct {
+		header string
+		want   lineRange
+		wantOK bool
+	}{
+		{header: "@@ -1,2 +3,4 @@", want: lineRange{start: 3, end: 6}, wantOK: true},
+		{header: "@@ -1 +7 @@ func foo()", want: lineRange{start: 7, end: 7}, wantOK: true},
+		{header: "@@ -5,2 +4,0 @@", wantOK: false},
+	} {
+		got, ok, err := parseHunkHeader(tt.header)
+		require.NoError(t, err, tt.header)
+		assert.Equal(t, tt.wantOK, ok, tt.header)
+		assert.Equal(t, tt.want, got, tt.header)
+	}
+	_, _, err := parseHunkHeader("@@ bogus @@")
+	require.Error(t, err)
+}
+
+func Test_targetMatcher(t *testing.T) {
+	t.Parallel()
+	result, err := overexported.Run(nil, &overexported.Options{
+		Dir: "testdata/reldir",
+		TargetMatcher: func(pkg *packages.Package) bool {
+			return pkg.PkgPath == "reldir/b"
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BUnused"}, exportNames(result.Exports))
+}
+
+func Test_pathBase(t *testing.T) {
+	t.Parallel()
+	result, err := overexported.Run([]string{"./..."}, &overexported.Options{
+		Test:     true,
+		Dir:      "testdata/foo",
+		PathBase: "testdata",
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Exports, 1)
+	assert.Equal(t, filepath.Join("foo", "foo.go"), result.Exports[0].Position.File)
+}
+
+// writeOtherRepo writes a module in its own git repository, outside of this
+// one, with an unused function in lib/lib.go. It returns its directory.
+func writeOtherRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, ".git"), 0o700))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "lib"), 0o700))
+	files := map[string]string{
+		"go.mod":     "module other\n\ngo 1.25.1\n",
+		"main.go":    "package main\n\nimport _ \"other/lib\"\n\nfunc main() {}\n",
+		"lib/lib.go": "package lib\n\nfunc Unused() {}\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	return dir
+}
+
+func Test_directiveScanners(t *testing.T) {
+	t.Parallel()
+	inject := func(_ *packages.Package, file *ast.File) []string {
+		var keys []string
+		for _, group := range file.Comments {
+			for _, c := range group.List {
+				if key, ok := strings.CutPrefix(c.Text, "//inject:"); ok {
+					keys = append(keys, key)
+				}
+			}
+		}
+		return keys
+	}
+	// provide names the function it is attached to, in the scanned package.
+	provide := func(pkg *packages.Package, file *ast.File) []string {
+		var keys []string
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Doc == nil {
+				continue
+			}
+			for _, c := range fn.Doc.List {
+				if c.Text == "//di:provide" {
+					keys = append(keys, pkg.PkgPath+"."+fn.Name.Name)
+				}
+			}
+		}
+		return keys
+	}
+	result, err := overexported.Run([]string{"./..."}, &overexported.Options{
+		Dir:               "testdata/directives",
+		DirectiveScanners: []overexported.DirectiveScanner{inject, provide},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Unused"}, exportNames(result.Exports))
+}
+
+func Test_isGenerated(t *testing.T) {
+	t.Parallel()
+	isGenerated := func(_ string, file *ast.File) bool {
+		for _, group := range file.Comments {
+			for _, c := range group.List {
+				if strings.HasPrefix(c.Text, "// AUTOGENERATED BY ") {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	opts := overexported.Options{Dir: "testdata/customgenerated"}
+
+	result, err := overexported.Run([]string{"./..."}, &opts)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Unused", "GeneratedUnused"}, exportNames(result.Exports))
+
+	opts.IsGenerated = isGenerated
+	result, err = overexported.Run([]string{"./..."}, &opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Unused"}, exportNames(result.Exports))
+
+	opts.Generated = true
+	result, err = overexported.Run([]string{"./..."}, &opts)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Unused", "GeneratedUnused"}, exportNames(result.Exports))
+
+	// Mock files are only recognized among the files isGenerated accepts.
+	opts = overexported.Options{Dir: "testdata/mockonly", MockOnly: true, IsGenerated: isGenerated}
+	result, err = overexported.Run([]string{"./..."}, &opts)
+	require.NoError(t, err)
+	assert.NotContains(t, exportNames(result.Exports), "Store")
+}
+
+// Benchmark_runSelfcheck analyzes this module, which is larger than any of the
+// fixtures.
+func Benchmark_runSelfcheck(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		err := run(io.Discard, []string{"selfcheck"})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Benchmark_runGenerated analyzes a generated module with many packages that
+// each use every export of the one before, so that usage tracking sees far
+// more references than in any fixture.
+func Benchmark_runGenerated(b *testing.B) {
+	const pkgCount, exportCount = 40, 100
+	dir := b.TempDir()
+	write := func(name, content string) {
+		b.Helper()
+		filename := filepath.Join(dir, name)
+		require.NoError(b, os.MkdirAll(filepath.Dir(filename), 0o700))
+		require.NoError(b, os.WriteFile(filename, []byte(content), 0o600))
+	}
+	write("go.mod", "module bench\n\ngo 1.25.1\n")
+	for i := range pkgCount {
+		var src strings.Builder
+		fmt.Fprintf(&src, "package p%d\n\n", i)
+		if i > 0 {
+			fmt.Fprintf(&src, "import \"bench/p%d\"\n\n", i-1)
+		}
+		for j := range exportCount {
+			fmt.Fprintf(&src, "type T%d struct{ F int }\n\n", j)
+			fmt.Fprintf(&src, "func (t T%d) M() int { return t.F }\n\n", j)
+			fmt.Fprintf(&src, "var V%d = %d\n\n", j, j)
+			fmt.Fprintf(&src, "func F%d() T%d { return T%d{F: V%d} }\n\n", j, j, j, j)
+		}
+		src.WriteString("func Entry() int {\n\tn := 0\n")
+		if i > 0 {
+			for j := range exportCount {
+				fmt.Fprintf(&src, "\tn += p%d.F%d().M() + p%d.V%d + p%d.T%d{}.F\n", i-1, j, i-1, j, i-1, j)
+			}
+			fmt.Fprintf(&src, "\tn += p%d.Entry()\n", i-1)
+		}
+		src.WriteString("\treturn n\n}\n")
+		write(fmt.Sprintf("p%d/p.go", i), src.String())
+	}
+	write("main.go", fmt.Sprintf("package main\n\nimport \"bench/p%d\"\n\nfunc main() {\n\tprintln(p%d.Entry())\n}\n", pkgCount-1, pkgCount-1))
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_, err := overexported.Run([]string{"./..."}, &overexported.Options{Dir: dir})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Test_runMerge(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeJSON := func(name string, v any) string {
+		t.Helper()
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		filename := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(filename, data, 0o600))
+		return filename
+	}
+	a := writeJSON("a.json", []overexported.Export{
+		{Name: "Both", Kind: "func", PkgPath: "p"},
+		{Name: "OnlyA", Kind: "func", PkgPath: "p"},
+	})
+	b := writeJSON("b.json", jsonEnvelope{Exports: []overexported.Export{
+		{Name: "Both", Kind: "func", PkgPath: "p"},
+		{Name: "OnlyA", Kind: "var", PkgPath: "p"},
+		{Name: "OnlyB", Kind: "func", PkgPath: "q"},
+	}})
+
+	t.Run("intersect", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "merge", "--intersect", a, b)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Both"}, exportNames(parseJSONOutput(t, stdout)))
+	})
+
+	t.Run("union", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "merge", "--union", a, b)
+		require.NoError(t, err)
+		exports := parseJSONOutput(t, stdout)
+		assert.Equal(t, []string{"Both", "OnlyA", "OnlyA", "OnlyB"}, exportNames(exports))
+	})
+
+	t.Run("empty intersection", func(t *testing.T) {
+		t.Parallel()
+		c := writeJSON("c.json", []overexported.Export{})
+		stdout, err := runOverexported(t, "merge", "--intersect", a, c)
+		require.NoError(t, err)
+		assert.Equal(t, "[]\n", stdout)
+	})
+
+	t.Run("requires an operation", func(t *testing.T) {
+		t.Parallel()
+		_, err := runOverexported(t, "merge", a, b)
+		require.Error(t, err)
+	})
+
+	t.Run("operations are exclusive", func(t *testing.T) {
+		t.Parallel()
+		_, err := runOverexported(t, "merge", "--intersect", "--union", a, b)
+		require.Error(t, err)
+	})
+}
+
+func Test_runAPIDiff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("text", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "apidiff", "testdata/apidiff/old", "testdata/apidiff/new")
+		require.NoError(t, err)
+		assert.Equal(t, "Used before, now over-exported:\n"+
+			"  apidiff/lib.Foo (func)\n"+
+			"Added:\n"+
+			"  apidiff/lib.Baz (func) over-exported\n", stdout)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "apidiff", "--json", "testdata/apidiff/old", "testdata/apidiff/new")
+		require.NoError(t, err)
+		var diff apiDiff
+		require.NoError(t, json.Unmarshal([]byte(stdout), &diff))
+		require.Len(t, diff.NewlyOverExported, 1)
+		assert.Equal(t, "Foo", diff.NewlyOverExported[0].Name)
+		require.Len(t, diff.Added, 1)
+		assert.Equal(t, "Baz", diff.Added[0].Name)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		t.Parallel()
+		stdout, err := runOverexported(t, "apidiff", "testdata/apidiff/new", "testdata/apidiff/new")
+		require.NoError(t, err)
+		assert.Equal(t, "No API changes found.\n", stdout)
 	})
 }
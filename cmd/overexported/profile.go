@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// startCPUProfile starts writing a CPU profile to filename. The returned
+// function stops profiling and closes the file.
+func startCPUProfile(filename string) (stop func() error, _ error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cpu profile: %w", err)
+	}
+	err = pprof.StartCPUProfile(f)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("cpu profile: %w", err), f.Close())
+	}
+	return func() error {
+		pprof.StopCPUProfile()
+		return f.Close()
+	}, nil
+}
+
+// writeMemProfile writes a heap profile to filename after a garbage
+// collection, so it reflects live memory.
+func writeMemProfile(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("memory profile: %w", err)
+	}
+	runtime.GC()
+	err = pprof.WriteHeapProfile(f)
+	if err != nil {
+		return errors.Join(fmt.Errorf("memory profile: %w", err), f.Close())
+	}
+	return f.Close()
+}
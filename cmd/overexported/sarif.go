@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/willabides/overexported/internal/overexported"
+)
+
+// sarifRules returns the rule for each export kind, in the order they are
+// listed in SARIF output.
+func sarifRules() []sarifRule {
+	return []sarifRule{
+		{ID: "overexported/func", ShortDescription: sarifText{"Exported function is only used in its package"}},
+		{ID: "overexported/method", ShortDescription: sarifText{"Exported method is only used in its package"}},
+		{ID: "overexported/type", ShortDescription: sarifText{"Exported type is only used in its package"}},
+		{ID: "overexported/var", ShortDescription: sarifText{"Exported variable is only used in its package"}},
+		{ID: "overexported/const", ShortDescription: sarifText{"Exported constant is only used in its package"}},
+	}
+}
+
+// The SARIF 2.1.0 types below cover only the properties overexported writes.
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string    `json:"id"`
+	ShortDescription sarifText `json:"shortDescription"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifText       `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// printResultSARIF prints the exports as a SARIF 2.1.0 log with one result
+// per export. File URIs are relative to root, which should be the root of the
+// analyzed repository since that is what code scanning services expect.
+func printResultSARIF(stdout io.Writer, result *overexported.Result, root string) error {
+	results := make([]sarifResult, 0, len(result.Exports))
+	for _, exp := range result.Exports {
+		results = append(results, sarifResult{
+			RuleID:  "overexported/" + exp.Kind,
+			Level:   "warning",
+			Message: sarifText{exp.Name + " (" + exp.Kind + ") can be unexported" + exportNotes(exp)},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: sarifURI(root, exp.Position.File)},
+					Region:           sarifRegion{StartLine: exp.Position.Line, StartColumn: exp.Position.Col},
+				},
+			}},
+		})
+	}
+	out := sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "overexported",
+				InformationURI: "https://github.com/willabides/overexported",
+				Rules:          sarifRules(),
+			}},
+			Results: results,
+		}},
+	}
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// sarifURI returns filename as a URI relative to root, or as an absolute
+// file URI if it is outside of root.
+func sarifURI(root, filename string) string {
+	if !filepath.IsAbs(filename) {
+		return (&url.URL{Path: filepath.ToSlash(filename)}).String()
+	}
+	relPath, err := filepath.Rel(root, filename)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return (&url.URL{Scheme: "file", Path: filepath.ToSlash(filename)}).String()
+	}
+	return (&url.URL{Path: filepath.ToSlash(relPath)}).String()
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/willabides/overexported/internal/overexported"
+	"golang.org/x/mod/modfile"
+)
+
+// selfModulePath is the module path of overexported.
+const selfModulePath = "github.com/willabides/overexported"
+
+const selfcheckDescription = `
+Analyze the overexported module itself and print the findings. Run it from a
+checkout of the module, or point -C at one. It exits nonzero if the analysis
+fails.
+`
+
+type selfcheckCmd struct {
+	Chdir string `short:"C" help:"Use the overexported checkout containing this directory instead of the current one."`
+}
+
+func runSelfcheck(stdout io.Writer, args []string) error {
+	var c selfcheckCmd
+	p, err := kong.New(&c,
+		kong.Name("overexported selfcheck"),
+		kong.Description(strings.TrimSpace(selfcheckDescription)),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = p.Parse(args)
+	if err != nil {
+		return err
+	}
+	return c.selfcheck(stdout)
+}
+
+func (c *selfcheckCmd) selfcheck(stdout io.Writer) error {
+	root, err := selfModuleRoot(c.Chdir)
+	if err != nil {
+		return err
+	}
+	result, err := overexported.Run([]string{"./..."}, &overexported.Options{
+		Test:                   true,
+		ExternalTestAsExternal: true,
+		Dir:                    root,
+	})
+	if err != nil {
+		return fmt.Errorf("selfcheck: %w", err)
+	}
+	sortExports(result.Exports, "name", false)
+	return printResult(stdout, result)
+}
+
+// selfModuleRoot returns the root of the overexported module containing dir,
+// or the current directory if dir is empty.
+func selfModuleRoot(dir string) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+	start, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("selfcheck: %w", err)
+	}
+	for dir := start; ; dir = filepath.Dir(dir) {
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			if modfile.ModulePath(data) != selfModulePath {
+				return "", fmt.Errorf("selfcheck: %s is not in a checkout of %s", start, selfModulePath)
+			}
+			return dir, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("selfcheck: %w", err)
+		}
+		if filepath.Dir(dir) == dir {
+			return "", fmt.Errorf("selfcheck: %s is not in a checkout of %s", start, selfModulePath)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+//go:build sqlite
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/willabides/overexported/internal/overexported"
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// sqliteSchema creates the table --sqlite appends findings to. run_time is
+// an RFC 3339 timestamp in UTC, which SQLite's date functions accept.
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS findings (
+	module TEXT NOT NULL,
+	pkg TEXT NOT NULL,
+	name TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	file TEXT NOT NULL,
+	line INTEGER NOT NULL,
+	run_time TEXT NOT NULL
+)`
+
+// writeSQLite appends the exports in result to the findings table of the
+// SQLite database in filename, creating the database and table as needed.
+// File names are relative to root when they are inside it. All rows of a
+// run are written in one transaction.
+func writeSQLite(filename string, result *overexported.Result, root string) (err error) {
+	db, err := sql.Open("sqlite", filename)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, db.Close()) }()
+	_, err = db.Exec(sqliteSchema)
+	if err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	err = insertFindings(tx, result, root)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
+}
+
+// insertFindings inserts a findings row for each export in result.
+func insertFindings(tx *sql.Tx, result *overexported.Result, root string) error {
+	stmt, err := tx.Prepare(`INSERT INTO findings (module, pkg, name, kind, file, line, run_time) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	runTime := result.GeneratedAt.UTC().Format(time.RFC3339)
+	for _, exp := range result.Exports {
+		_, err = stmt.Exec(
+			result.PackageModules[exp.PkgPath],
+			exp.PkgPath,
+			exp.Name,
+			exp.Kind,
+			sqliteFile(root, exp.Position.File),
+			exp.Position.Line,
+			runTime,
+		)
+		if err != nil {
+			return errors.Join(err, stmt.Close())
+		}
+	}
+	return stmt.Close()
+}
+
+// sqliteFile returns filename relative to root with forward slashes, or
+// filename itself when it is outside root.
+func sqliteFile(root, filename string) string {
+	relPath, err := filepath.Rel(root, filename)
+	if err != nil || !filepath.IsLocal(relPath) {
+		return filepath.ToSlash(filename)
+	}
+	return filepath.ToSlash(relPath)
+}
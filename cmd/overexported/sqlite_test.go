@@ -0,0 +1,53 @@
+//go:build sqlite
+
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_run_sqlite(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	dbFile := filepath.Join(t.TempDir(), "findings.db")
+
+	// Each run appends its rows, so two runs leave two copies.
+	for range 2 {
+		_, err := runOverexported(t, "-C", "testdata/foo", "--sqlite", dbFile, "./...")
+		require.NoError(t, err)
+	}
+
+	db, err := sql.Open("sqlite", dbFile)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+	rows, err := db.Query(`SELECT module, pkg, name, kind, file, line, run_time FROM findings`)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, rows.Close()) })
+
+	type finding struct {
+		module, pkg, name, kind, file string
+		line                          int
+		runTime                       string
+	}
+	var got []finding
+	for rows.Next() {
+		var f finding
+		require.NoError(t, rows.Scan(&f.module, &f.pkg, &f.name, &f.kind, &f.file, &f.line, &f.runTime))
+		got = append(got, f)
+	}
+	require.NoError(t, rows.Err())
+	want := finding{
+		module:  "baz/foo",
+		pkg:     "baz/foo",
+		name:    "Bar",
+		kind:    "func",
+		file:    "cmd/overexported/testdata/foo/foo.go",
+		line:    7,
+		runTime: "2023-11-14T22:13:20Z",
+	}
+	assert.Equal(t, []finding{want, want}, got)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/willabides/overexported/internal/overexported"
+)
+
+// lineRange is an inclusive range of line numbers.
+type lineRange struct {
+	start, end int
+}
+
+// stagedLines returns the lines added or changed in the git index, keyed by
+// absolute filename.
+func stagedLines(dir string) (map[string][]lineRange, error) {
+	if dir == "" {
+		dir = "."
+	}
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, fmt.Errorf("find git repository: %w", err)
+	}
+	root := strings.TrimSpace(string(out))
+	// Fix the prefixes and path quoting that parseDiffLines expects, whatever
+	// the user's diff.noprefix, diff.mnemonicPrefix and core.quotePath say.
+	diff, err := exec.Command("git", "-C", dir, "-c", "core.quotePath=false",
+		"diff", "--cached", "--unified=0", "--no-color", "--no-ext-diff",
+		"--src-prefix=a/", "--dst-prefix=b/",
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff --cached: %w", err)
+	}
+	return parseDiffLines(root, diff)
+}
+
+// parseDiffLines returns the new-side line ranges of the hunks in a unified
+// diff with "b/" new-side prefixes, keyed by filename joined to root.
+func parseDiffLines(root string, diff []byte) (map[string][]lineRange, error) {
+	lines := make(map[string][]lineRange)
+	var filename string
+	scanner := bufio.NewScanner(bytes.NewReader(diff))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			filename = ""
+			name := diffPath(line[len("+++ "):])
+			name, ok := strings.CutPrefix(name, "b/")
+			if ok {
+				filename = filepath.Join(root, filepath.FromSlash(name))
+			}
+		case strings.HasPrefix(line, "@@ ") && filename != "":
+			r, ok, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				lines[filename] = append(lines[filename], r)
+			}
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// diffPath returns the path in a "+++" line of a diff. Git adds a trailing
+// tab to paths with spaces and quotes paths with unusual characters.
+func diffPath(s string) string {
+	s = strings.TrimSuffix(s, "\t")
+	if strings.HasPrefix(s, `"`) {
+		unquoted, err := strconv.Unquote(s)
+		if err == nil {
+			return unquoted
+		}
+	}
+	return s
+}
+
+// parseHunkHeader returns the new-side range of a hunk header like
+// "@@ -1,2 +3,4 @@". It returns false for hunks that only delete lines.
+func parseHunkHeader(header string) (lineRange, bool, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return lineRange{}, false, fmt.Errorf("invalid hunk header: %q", header)
+	}
+	startStr, countStr, hasCount := strings.Cut(fields[2][1:], ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return lineRange{}, false, fmt.Errorf("invalid hunk header: %q", header)
+	}
+	count := 1
+	if hasCount {
+		count, err = strconv.Atoi(countStr)
+		if err != nil {
+			return lineRange{}, false, fmt.Errorf("invalid hunk header: %q", header)
+		}
+	}
+	if count == 0 {
+		return lineRange{}, false, nil
+	}
+	return lineRange{start: start, end: start + count - 1}, true, nil
+}
+
+// filterStaged removes exports whose declaration line isn't in lines.
+func filterStaged(exports []overexported.Export, lines map[string][]lineRange) []overexported.Export {
+	return slices.DeleteFunc(exports, func(exp overexported.Export) bool {
+		return !slices.ContainsFunc(lines[exp.Position.File], func(r lineRange) bool {
+			return exp.Position.Line >= r.start && exp.Position.Line <= r.end
+		})
+	})
+}
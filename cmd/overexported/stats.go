@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/willabides/overexported/internal/overexported"
+)
+
+// exportStats counts the exports of a package, or of all target packages.
+type exportStats struct {
+	PkgPath      string `json:"package,omitempty"`
+	Exported     int    `json:"exported"`
+	Used         int    `json:"used"`
+	OverExported int    `json:"overExported"`
+	// Utilization is the percentage of exports that are used.
+	Utilization float64 `json:"utilization"`
+}
+
+// statsOutput is the JSON output of --stats.
+type statsOutput struct {
+	Packages []exportStats `json:"packages"`
+	Total    exportStats   `json:"total"`
+}
+
+func (s *exportStats) add(entry overexported.InventoryEntry) {
+	s.Exported++
+	switch entry.Status {
+	case "used":
+		s.Used++
+	case "over-exported":
+		s.OverExported++
+	}
+	s.Utilization = float64(s.Used) / float64(s.Exported) * 100
+}
+
+// computeStats counts exports per package and overall from an inventory
+// sorted by package. Exports that are kept because they are generated or
+// suppressed count toward Exported only.
+func computeStats(inventory []overexported.InventoryEntry) statsOutput {
+	out := statsOutput{Packages: []exportStats{}}
+	for _, entry := range inventory {
+		if len(out.Packages) == 0 || out.Packages[len(out.Packages)-1].PkgPath != entry.PkgPath {
+			out.Packages = append(out.Packages, exportStats{PkgPath: entry.PkgPath})
+		}
+		out.Packages[len(out.Packages)-1].add(entry)
+		out.Total.add(entry)
+	}
+	return out
+}
+
+// printStats prints export counts per package and overall as a table or as
+// a JSON object.
+func printStats(stdout io.Writer, result *overexported.Result, asJSON bool) error {
+	stats := computeStats(result.Inventory)
+	if asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+	if len(stats.Packages) == 0 {
+		_, err := fmt.Fprintln(stdout, "No exported identifiers found.")
+		return err
+	}
+	// The rows are collected in a buffer so that the table is written, and
+	// its error checked, in one write.
+	var rows bytes.Buffer
+	rows.WriteString("PACKAGE\tEXPORTED\tUSED\tOVER-EXPORTED\tUTILIZATION\n")
+	total := stats.Total
+	total.PkgPath = "TOTAL"
+	for _, s := range append(stats.Packages, total) {
+		fmt.Fprintf(&rows, "%s\t%d\t%d\t%d\t%.1f%%\n", s.PkgPath, s.Exported, s.Used, s.OverExported, s.Utilization)
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	_, err := tw.Write(rows.Bytes())
+	if err != nil {
+		return err
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,10 @@
+package main
+
+import "aliasmethods/lib"
+
+func main() {
+	var t lib.T
+	t.ViaAlias()
+	_ = lib.D{}
+	lib.Ext{}.Method()
+}
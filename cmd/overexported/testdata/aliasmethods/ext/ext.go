@@ -0,0 +1,7 @@
+package ext
+
+// E is aliased by lib.
+type E struct{}
+
+// Method is called through lib.Ext.
+func (E) Method() {}
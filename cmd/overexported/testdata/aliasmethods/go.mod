@@ -0,0 +1,3 @@
+module aliasmethods
+
+go 1.25.1
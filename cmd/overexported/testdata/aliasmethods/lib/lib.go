@@ -0,0 +1,27 @@
+package lib
+
+import "aliasmethods/ext"
+
+// T is used externally.
+type T struct{}
+
+// A is an alias for T. Methods declared with A as the receiver belong to T.
+type A = T
+
+// ViaAlias is declared through the alias and called externally.
+func (A) ViaAlias() {}
+
+// UnusedViaAlias is declared through the alias and not used externally.
+func (*A) UnusedViaAlias() {}
+
+// Direct is not used externally.
+func (T) Direct() {}
+
+// D is a definition with T as its underlying type. It has its own methods.
+type D T
+
+// OnDefinition is not used externally.
+func (D) OnDefinition() {}
+
+// Ext is an alias for a type in another package, whose methods stay there.
+type Ext = ext.E
@@ -0,0 +1,7 @@
+package main
+
+import "apidiff/lib"
+
+func main() {
+	lib.Bar()
+}
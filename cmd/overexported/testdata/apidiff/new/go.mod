@@ -0,0 +1,3 @@
+module apidiff
+
+go 1.25.1
@@ -0,0 +1,10 @@
+package lib
+
+// Foo is no longer used by cmd.
+func Foo() {}
+
+// Bar is used by cmd.
+func Bar() {}
+
+// Baz is new.
+func Baz() {}
@@ -0,0 +1,8 @@
+package main
+
+import "apidiff/lib"
+
+func main() {
+	lib.Foo()
+	lib.Bar()
+}
@@ -0,0 +1,7 @@
+package lib
+
+// Foo is used by cmd.
+func Foo() {}
+
+// Bar is used by cmd.
+func Bar() {}
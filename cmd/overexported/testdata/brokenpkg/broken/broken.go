@@ -0,0 +1,9 @@
+package broken
+
+import "brokenpkg"
+
+// Broken doesn't compile.
+func Broken() int {
+	brokenpkg.Unused()
+	return "not an int"
+}
@@ -0,0 +1,6 @@
+package broken2
+
+// Broken2 doesn't compile either.
+func Broken2() int {
+	return undefined
+}
@@ -0,0 +1,7 @@
+package main
+
+import "brokenpkg"
+
+func main() {
+	brokenpkg.Used()
+}
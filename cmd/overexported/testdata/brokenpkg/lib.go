@@ -0,0 +1,7 @@
+package brokenpkg
+
+// Used is used by cmd.
+func Used() {}
+
+// Unused is not used externally.
+func Unused() {}
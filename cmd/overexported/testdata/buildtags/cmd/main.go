@@ -0,0 +1,7 @@
+package main
+
+import "buildtags/store"
+
+func main() {
+	store.New().Put("x")
+}
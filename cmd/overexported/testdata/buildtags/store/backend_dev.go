@@ -0,0 +1,18 @@
+//go:build !prod
+
+package store
+
+import "buildtags/codec"
+
+// DevBackend is the Backend for development builds.
+type DevBackend struct{}
+
+// Put implements Backend.
+func (DevBackend) Put(s string) {
+	codec.DevEncode(s)
+}
+
+// New returns the Backend for this build.
+func New() Backend {
+	return DevBackend{}
+}
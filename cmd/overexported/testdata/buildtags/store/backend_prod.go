@@ -0,0 +1,18 @@
+//go:build prod
+
+package store
+
+import "buildtags/codec"
+
+// ProdBackend is the Backend for production builds.
+type ProdBackend struct{}
+
+// Put implements Backend.
+func (ProdBackend) Put(s string) {
+	codec.ProdEncode(s)
+}
+
+// New returns the Backend for this build.
+func New() Backend {
+	return ProdBackend{}
+}
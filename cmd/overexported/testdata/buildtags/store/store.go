@@ -0,0 +1,6 @@
+package store
+
+// Backend stores values.
+type Backend interface {
+	Put(s string)
+}
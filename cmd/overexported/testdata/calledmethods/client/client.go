@@ -0,0 +1,36 @@
+package client
+
+// Client is taken as a parameter by consumer, which only calls Get.
+type Client struct{}
+
+// Get is called by consumer.
+func (*Client) Get() string {
+	return ""
+}
+
+// Put is only used within this package.
+func (*Client) Put(string) {}
+
+// Close is not used.
+func (*Client) Close() error {
+	return nil
+}
+
+// New returns a Client and calls Put.
+func New() *Client {
+	c := &Client{}
+	c.Put("")
+	return c
+}
+
+// Options is taken as a parameter by consumer but has no methods.
+type Options struct{}
+
+// Local is only taken as a parameter in this package.
+type Local struct{}
+
+// Method is not used externally.
+func (Local) Method() {}
+
+// UseLocal takes a Local.
+func UseLocal(Local) {}
@@ -0,0 +1,10 @@
+package main
+
+import (
+	"calledmethods/client"
+	"calledmethods/consumer"
+)
+
+func main() {
+	println(consumer.Fetch(client.New(), client.Options{}))
+}
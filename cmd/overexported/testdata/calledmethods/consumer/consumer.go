@@ -0,0 +1,8 @@
+package consumer
+
+import "calledmethods/client"
+
+// Fetch only needs Get.
+func Fetch(c *client.Client, _ client.Options) string {
+	return c.Get()
+}
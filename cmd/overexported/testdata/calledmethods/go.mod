@@ -0,0 +1,3 @@
+module calledmethods
+
+go 1.25.1
@@ -0,0 +1,7 @@
+package main
+
+import "cgoexport/lib"
+
+func main() {
+	lib.Run()
+}
@@ -0,0 +1,3 @@
+module cgoexport
+
+go 1.25.1
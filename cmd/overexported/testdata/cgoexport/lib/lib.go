@@ -0,0 +1,21 @@
+package lib
+
+/*
+extern void GoCallback(void);
+
+static void callGo(void) { GoCallback(); }
+*/
+import "C"
+
+// GoCallback is only called from C.
+//
+//export GoCallback
+func GoCallback() {}
+
+// Run calls into C, which calls GoCallback.
+func Run() {
+	C.callGo()
+}
+
+// Unused is not used anywhere.
+func Unused() {}
@@ -0,0 +1,23 @@
+package a
+
+// Foo is only referenced inside a closure defined in package b.
+func Foo() int {
+	return 1
+}
+
+// Bar is only referenced inside a closure nested in another closure in
+// package b.
+func Bar() int {
+	return 2
+}
+
+// Value is only read inside a closure defined in package b.
+var Value = 3
+
+// Unused is not used externally.
+func Unused() {}
+
+// Baz is only referenced inside a closure in a generic function in package b.
+func Baz() int {
+	return 4
+}
@@ -0,0 +1,24 @@
+package b
+
+import "closurexref/a"
+
+// Callbacks returns closures that reference package a. They are only called
+// from package c.
+func Callbacks() []func() int {
+	return []func() int{
+		func() int { return a.Foo() },
+		func() int {
+			inner := func() int { return a.Bar() }
+			return inner() + a.Value
+		},
+	}
+}
+
+// Generic returns a closure that references package a from a generic
+// function, so the closure belongs to an instantiation.
+func Generic[T any](v T) func() int {
+	return func() int {
+		_ = v
+		return a.Baz()
+	}
+}
@@ -0,0 +1,10 @@
+package c
+
+// Sum calls each callback and adds up the results.
+func Sum(callbacks []func() int) int {
+	total := 0
+	for _, cb := range callbacks {
+		total += cb()
+	}
+	return total
+}
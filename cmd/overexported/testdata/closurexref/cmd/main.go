@@ -0,0 +1,11 @@
+package main
+
+import (
+	"closurexref/b"
+	"closurexref/c"
+)
+
+func main() {
+	callbacks := append(b.Callbacks(), b.Generic("x"))
+	println(c.Sum(callbacks))
+}
@@ -0,0 +1,3 @@
+module closurexref
+
+go 1.25.1
@@ -0,0 +1,10 @@
+package main
+
+import (
+	"constraints/gen"
+	"constraints/num"
+)
+
+func main() {
+	println(gen.Sum(num.Meters(1), num.Meters(2)))
+}
@@ -0,0 +1,12 @@
+package gen
+
+import "constraints/num"
+
+// Sum adds up the values of xs.
+func Sum[T num.Number](xs ...T) int {
+	total := 0
+	for _, x := range xs {
+		total += x.Value()
+	}
+	return total
+}
@@ -0,0 +1,15 @@
+package num
+
+// Number is used as a constraint by gen.
+type Number interface {
+	Value() int
+}
+
+// Meters satisfies Number and is passed to gen.Sum by cmd.
+type Meters int
+
+// Value is required by Number.
+func (m Meters) Value() int { return int(m) }
+
+// Extra is not required by Number and never called.
+func (m Meters) Extra() {}
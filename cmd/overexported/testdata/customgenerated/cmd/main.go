@@ -0,0 +1,12 @@
+package main
+
+import (
+	"fmt"
+
+	"customgenerated/lib"
+)
+
+func main() {
+	lib.Used()
+	fmt.Println(lib.Red, lib.Green)
+}
@@ -0,0 +1,3 @@
+module customgenerated
+
+go 1.25.1
@@ -0,0 +1,9 @@
+package lib
+
+// Color is formatted by its stringer-generated String method.
+type Color int
+
+const (
+	Red Color = iota
+	Green
+)
@@ -0,0 +1,5 @@
+package lib
+
+func Used() {}
+
+func Unused() {}
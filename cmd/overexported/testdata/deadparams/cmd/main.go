@@ -0,0 +1,7 @@
+package main
+
+import "deadparams/lib"
+
+func main() {
+	lib.Run()
+}
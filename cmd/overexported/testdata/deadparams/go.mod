@@ -0,0 +1,3 @@
+module deadparams
+
+go 1.25.1
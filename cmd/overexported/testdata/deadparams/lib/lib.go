@@ -0,0 +1,30 @@
+package lib
+
+// Config is only taken by reported functions.
+type Config struct{}
+
+// Option is only taken by reported functions.
+type Option func(*Config)
+
+// Used is used externally.
+type Used struct{}
+
+// New is not used externally and takes reported types.
+func New(cfg *Config, opts ...Option) *Used {
+	return &Used{}
+}
+
+// Apply is not used externally and takes a reported type.
+func (u *Used) Apply(opts map[string][]Option) {}
+
+// Plain is not used externally and takes no reported types.
+func Plain(u Used) {}
+
+// Run is used externally.
+func Run() Used {
+	New(nil)
+	var u Used
+	u.Apply(nil)
+	Plain(u)
+	return u
+}
@@ -0,0 +1,7 @@
+package main
+
+import "dedup/lib"
+
+func main() {
+	_, _ = lib.Parse("1")
+}
@@ -0,0 +1,27 @@
+package lib
+
+import "strconv"
+
+// ParseDecimal is not used externally and has the same signature as
+// ParseNumber.
+func ParseDecimal(s string) (int, error) {
+	return strconv.Atoi(s)
+}
+
+// ParseNumber is not used externally and has the same signature as
+// ParseDecimal.
+func ParseNumber(s string) (int, error) {
+	n, err := strconv.ParseInt(s, 10, 0)
+	return int(n), err
+}
+
+// Format is not used externally and shares its signature with no other
+// function.
+func Format(n int) string {
+	return strconv.Itoa(n)
+}
+
+// Parse is used externally, so it isn't a duplicate candidate.
+func Parse(s string) (int, error) {
+	return strconv.Atoi(s)
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"sync"
+
+	"deferredgo/lib"
+)
+
+func main() {
+	defer lib.Deferred()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go lib.Background(&wg)
+	w := &lib.Worker{}
+	defer w.Close()
+	go w.Start(&wg)
+	wg.Wait()
+}
@@ -0,0 +1,3 @@
+module deferredgo
+
+go 1.25.1
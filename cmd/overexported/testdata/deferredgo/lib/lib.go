@@ -0,0 +1,21 @@
+package lib
+
+import "sync"
+
+// Deferred is only called with a defer statement from another package.
+func Deferred() {}
+
+// Background is only called with a go statement from another package.
+func Background(wg *sync.WaitGroup) { wg.Done() }
+
+// Worker has methods called with defer and go statements.
+type Worker struct{}
+
+// Close is only called with a defer statement from another package.
+func (*Worker) Close() {}
+
+// Start is only called with a go statement from another package.
+func (*Worker) Start(wg *sync.WaitGroup) { wg.Done() }
+
+// Unused is not called outside this package.
+func Unused() {}
@@ -0,0 +1,7 @@
+package main
+
+import "deprecated/lib"
+
+func main() {
+	_ = lib.T{}
+}
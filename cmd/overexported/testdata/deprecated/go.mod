@@ -0,0 +1,3 @@
+module deprecated
+
+go 1.25.1
@@ -0,0 +1,29 @@
+package lib
+
+// Old is not used externally.
+//
+// Deprecated: Use New instead.
+func Old() {}
+
+// New is not used externally.
+func New() {}
+
+// Mentioned is not used externally. Its doc mentions Deprecated: without
+// starting a paragraph with it.
+func Mentioned() {}
+
+// Deprecated: Use Limit instead.
+const OldLimit = 1
+
+const (
+	// Limit is not used externally.
+	Limit = 2
+)
+
+// T is used externally.
+type T struct{}
+
+// OldMethod is not used externally.
+//
+// Deprecated: Use T directly.
+func (T) OldMethod() {}
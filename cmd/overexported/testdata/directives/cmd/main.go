@@ -0,0 +1,4 @@
+package main
+
+//inject:directives/lib.Wired
+func main() {}
@@ -0,0 +1,12 @@
+package lib
+
+// Wired is only referenced by an injection marker.
+func Wired() {}
+
+// Provided is registered by a marker on its own declaration.
+//
+//di:provide
+func Provided() {}
+
+// Unused is not referenced at all.
+func Unused() {}
@@ -0,0 +1,10 @@
+package a
+
+// Helper is used by cmd through a dot-import.
+func Helper() {}
+
+// Base is embedded by cmd through a dot-import.
+type Base struct{}
+
+// Describe is called on cmd's embedding type.
+func (Base) Describe() string { return "base" }
@@ -0,0 +1,13 @@
+package c
+
+// Helper has the same name as a.Helper but is never used.
+func Helper() {}
+
+// Base has the same name as a.Base but is never used.
+type Base struct{}
+
+// Describe has the same name as a.Base.Describe but is never used.
+func (Base) Describe() string { return "c" }
+
+// Other is used by cmd.
+func Other() {}
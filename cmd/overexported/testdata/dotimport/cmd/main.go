@@ -0,0 +1,16 @@
+package main
+
+import (
+	. "dotimport/a"
+	"dotimport/c"
+)
+
+type wrapper struct {
+	Base
+}
+
+func main() {
+	Helper()
+	c.Other()
+	println(wrapper{}.Describe())
+}
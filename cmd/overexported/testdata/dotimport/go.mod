@@ -0,0 +1,3 @@
+module dotimport
+
+go 1.25.1
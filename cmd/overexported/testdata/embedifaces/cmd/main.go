@@ -0,0 +1,22 @@
+package main
+
+import (
+	"io"
+
+	"embedifaces"
+)
+
+// A embeds io.Reader and embedifaces.Fooer.
+type A interface {
+	io.Reader
+	embedifaces.Fooer
+}
+
+func main() {
+	var x A = &embedifaces.C{}
+	use(x)
+}
+
+func use(a A) {
+	_ = a
+}
@@ -0,0 +1,22 @@
+package embedifaces
+
+import "io"
+
+// Fooer is satisfied by C.
+type Fooer interface {
+	Foo()
+}
+
+// C satisfies Fooer and io.Reader.
+type C struct{}
+
+// Read implements io.Reader.
+func (c *C) Read(p []byte) (n int, err error) {
+	return 0, io.EOF
+}
+
+// Foo implements Fooer.
+func (c *C) Foo() {}
+
+// Bar is not part of any interface.
+func (c *C) Bar() {}
@@ -0,0 +1,3 @@
+module embedifaces
+
+go 1.25.1
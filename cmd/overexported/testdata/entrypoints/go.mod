@@ -0,0 +1,3 @@
+module entrypoints
+
+go 1.25.1
@@ -0,0 +1,15 @@
+package handlers
+
+import "entrypoints/lib"
+
+func HandleFoo() string {
+	return lib.Helper()
+}
+
+func HandleBar() string {
+	return "bar"
+}
+
+func NotAHandler() string {
+	return "not a handler"
+}
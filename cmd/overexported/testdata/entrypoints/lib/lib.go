@@ -0,0 +1,9 @@
+package lib
+
+func Helper() string {
+	return "helper"
+}
+
+func Unused() string {
+	return "unused"
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"enums/colors"
+)
+
+func main() {
+	fmt.Println(colors.ColorRed.Name(), colors.ColorGreen.Name())
+}
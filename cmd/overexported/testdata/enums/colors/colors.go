@@ -0,0 +1,33 @@
+package colors
+
+// Color is an enum.
+type Color int
+
+// Color values.
+const (
+	ColorRed Color = iota
+	ColorGreen
+	ColorPurple
+)
+
+// Name returns the name of c.
+func (c Color) Name() string {
+	switch c {
+	case ColorRed:
+		return "red"
+	case ColorGreen:
+		return "green"
+	case ColorPurple:
+		return "purple"
+	}
+	return ""
+}
+
+// Lonely is the only constant of its type.
+type Lonely string
+
+// OnlyLonely is not part of an enum.
+const OnlyLonely Lonely = "lonely"
+
+// Untyped is not part of an enum.
+const Untyped = 1
@@ -0,0 +1,3 @@
+module enums
+
+go 1.25.1
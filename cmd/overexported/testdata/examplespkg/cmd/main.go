@@ -0,0 +1,7 @@
+package main
+
+import "examplespkg"
+
+func main() {
+	examplespkg.RealUse()
+}
@@ -0,0 +1,8 @@
+package main
+
+import "examplespkg"
+
+func main() {
+	examplespkg.RealUse()
+	examplespkg.ExampleOnly()
+}
@@ -0,0 +1,3 @@
+module examplespkg
+
+go 1.25.1
@@ -0,0 +1,10 @@
+package examplespkg
+
+// RealUse is used by cmd and the examples.
+func RealUse() {}
+
+// ExampleOnly is only used by the examples.
+func ExampleOnly() {}
+
+// Unused is not used externally.
+func Unused() {}
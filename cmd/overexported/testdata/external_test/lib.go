@@ -19,3 +19,9 @@ func NotUsedInTests() string {
 func OnlyUsedInTests() string {
 	return "only tests"
 }
+
+// OnlyUsedInInternalTest is only used by the internal test package, which is
+// part of this package even when it is compiled into a test variant.
+func OnlyUsedInInternalTest() string {
+	return "only internal tests"
+}
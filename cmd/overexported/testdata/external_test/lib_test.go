@@ -4,4 +4,5 @@ import "testing"
 
 func TestInternal(t *testing.T) {
 	_ = UsedInInternalTest()
+	_ = OnlyUsedInInternalTest()
 }
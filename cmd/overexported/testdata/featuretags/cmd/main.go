@@ -0,0 +1,7 @@
+package main
+
+import "featuretags/lib"
+
+func main() {
+	lib.Stable()
+}
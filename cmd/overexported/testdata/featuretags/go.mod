@@ -0,0 +1,3 @@
+module featuretags
+
+go 1.25.1
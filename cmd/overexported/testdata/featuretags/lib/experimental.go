@@ -0,0 +1,7 @@
+//go:build experimental
+
+package lib
+
+// Experimental is only built with the experimental tag and not used
+// externally.
+func Experimental() {}
@@ -0,0 +1,7 @@
+package lib
+
+// Stable is used externally.
+func Stable() {}
+
+// Unused is not used externally.
+func Unused() {}
@@ -0,0 +1,6 @@
+//go:build linux && !legacy
+
+package lib
+
+// LinuxOnly is not used externally.
+func LinuxOnly() {}
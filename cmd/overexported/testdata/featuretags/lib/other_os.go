@@ -0,0 +1,6 @@
+//go:build plan9
+
+package lib
+
+// OtherOS is not used externally.
+func OtherOS() {}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+
+	"genericembed/lib"
+)
+
+func main() {
+	w := lib.Wrapper[int]{Value: 1}
+	fmt.Println(w.Inner(), w.Get())
+	_ = lib.Wrapper[string]{}
+	p := lib.PtrWrapper[string]{PtrBase: &lib.PtrBase{}}
+	fmt.Println(p.PtrInner())
+}
@@ -0,0 +1,3 @@
+module genericembed
+
+go 1.25.1
@@ -0,0 +1,25 @@
+package lib
+
+type Base struct{}
+
+func (Base) Inner() string { return "inner" }
+
+func (Base) UnusedInner() string { return "unused" }
+
+type Wrapper[T any] struct {
+	Base
+	Value T
+}
+
+func (w Wrapper[T]) Get() T { return w.Value }
+
+func (w Wrapper[T]) UnusedGet() T { return w.Value }
+
+type PtrBase struct{}
+
+func (*PtrBase) PtrInner() string { return "ptr" }
+
+type PtrWrapper[T any] struct {
+	*PtrBase
+	Value T
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"fmt"
+
+	"genericnames/lib"
+)
+
+func main() {
+	var a lib.A[int]
+	fmt.Println(a.Get(), lib.Same(1))
+}
@@ -0,0 +1,3 @@
+module genericnames
+
+go 1.25.1
@@ -0,0 +1,22 @@
+package lib
+
+// A and B are generic types with identical shapes and method names.
+type A[T any] struct{ v T }
+
+// Get is used on an instantiation of A.
+func (a A[T]) Get() T { return a.v }
+
+// B is not used outside this package.
+type B[T any] struct{ v T }
+
+// Get has the same name as A.Get but is not used.
+func (b B[T]) Get() T { return b.v }
+
+// Same is used with the same type arguments as Other would be.
+func Same[T any](v T) T { return v }
+
+// Other is not used outside this package.
+func Other[T any](v T) T { return v }
+
+// Get shares its name with the methods but is not used outside this package.
+func Get() string { return "" }
@@ -6,8 +6,12 @@ import (
 	"generics"
 )
 
+type local struct{}
+
 func main() {
 	fmt.Println(generics.UsedGeneric(42))
+	fmt.Println(generics.UsedGeneric("hello"))
 	t := generics.UsedGenericType[string]{Value: "hello"}
 	fmt.Println(t.Get())
+	fmt.Println(generics.UsedWithLocalType(local{}))
 }
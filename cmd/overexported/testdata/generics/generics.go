@@ -29,3 +29,8 @@ type UnusedGenericType[T any] struct {
 func (u UnusedGenericType[T]) Get() T {
 	return u.Value
 }
+
+// UsedWithLocalType is only instantiated with a type local to the caller.
+func UsedWithLocalType[T any](v T) T {
+	return v
+}
@@ -0,0 +1,10 @@
+package main
+
+import "genregistry/registry"
+
+func main() {
+	registry.Init()
+	for _, h := range registry.Handlers {
+		h()
+	}
+}
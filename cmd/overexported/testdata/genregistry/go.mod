@@ -0,0 +1,3 @@
+module genregistry
+
+go 1.25.1
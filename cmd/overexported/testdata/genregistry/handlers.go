@@ -0,0 +1,10 @@
+package genregistry
+
+// Registered is only referenced from a generated registration table.
+func Registered() {}
+
+// Unregistered is not referenced anywhere.
+func Unregistered() {}
+
+// CalledFromGenerated is only called directly from generated code.
+func CalledFromGenerated() {}
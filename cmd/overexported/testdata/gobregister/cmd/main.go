@@ -0,0 +1,18 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"gobregister/msg"
+)
+
+func main() {
+	gob.Register(msg.Event{})
+	var payload any
+	var buf bytes.Buffer
+	err := gob.NewDecoder(&buf).Decode(&payload)
+	if err != nil {
+		panic(err)
+	}
+}
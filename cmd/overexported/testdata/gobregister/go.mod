@@ -0,0 +1,3 @@
+module gobregister
+
+go 1.25.1
@@ -0,0 +1,13 @@
+package msg
+
+// Event is registered with gob by cmd. Its fields are only accessed by gob
+// through reflection.
+type Event struct {
+	Name  string
+	Count int
+}
+
+// Unused is not used externally.
+type Unused struct {
+	Field string
+}
@@ -0,0 +1,7 @@
+package main
+
+import "gogenerate/lib"
+
+func main() {
+	lib.Run()
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"os"
+
+	"gogenerate/lib"
+)
+
+func main() {
+	_ = os.WriteFile("zz_generated.go", []byte(lib.Template), 0o600)
+}
@@ -0,0 +1,3 @@
+module gogenerate
+
+go 1.25.1
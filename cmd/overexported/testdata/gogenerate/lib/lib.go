@@ -0,0 +1,12 @@
+package lib
+
+//go:generate go run gogenerate/cmd/gen
+
+// Template is only used by the generator.
+const Template = "package lib\n"
+
+// Run is used by the app.
+func Run() {}
+
+// Unused is not used outside this package.
+func Unused() {}
@@ -0,0 +1,10 @@
+package main
+
+import (
+	_ "vend/lib"
+	"vend/x"
+)
+
+func main() {
+	println(x.X())
+}
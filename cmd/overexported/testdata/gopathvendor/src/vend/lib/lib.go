@@ -0,0 +1,9 @@
+package lib
+
+// Foo is only used through the copy vendored by vend/x.
+func Foo() int {
+	return 1
+}
+
+// Unused is not used externally.
+func Unused() {}
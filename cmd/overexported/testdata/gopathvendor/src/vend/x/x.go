@@ -0,0 +1,9 @@
+package x
+
+// In GOPATH mode, this resolves to vend/x/vendor/vend/lib.
+import "vend/lib"
+
+// X calls the vendored copy of lib.
+func X() int {
+	return lib.Foo()
+}
@@ -0,0 +1,11 @@
+package main
+
+import "ifaceimpl/lib"
+
+type plugin struct{}
+
+func (*plugin) Run() {}
+
+func main() {
+	lib.Use()
+}
@@ -0,0 +1,3 @@
+module ifaceimpl
+
+go 1.25.1
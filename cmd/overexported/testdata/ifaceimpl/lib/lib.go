@@ -0,0 +1,20 @@
+package lib
+
+// InternalOnly is only implemented inside this package.
+type InternalOnly interface {
+	Do()
+}
+
+type impl struct{}
+
+func (impl) Do() {}
+
+var _ InternalOnly = impl{}
+
+// Plugin is implemented by a type in another package that never names it.
+type Plugin interface {
+	Run()
+}
+
+// Use is used by main.
+func Use() {}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"fmt"
+
+	"ifacemap/lib"
+)
+
+func main() {
+	var b lib.Buffer
+	fmt.Fprint(&b, "x")
+	fmt.Println(b.String())
+}
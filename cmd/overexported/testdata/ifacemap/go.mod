@@ -0,0 +1,3 @@
+module ifacemap
+
+go 1.25.1
@@ -0,0 +1,30 @@
+package lib
+
+import "fmt"
+
+// Flusher is satisfied by *Buffer.
+type Flusher interface {
+	Flush()
+}
+
+// Buffer collects output.
+type Buffer struct {
+	data []byte
+}
+
+// Write appends p.
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.data = append(b.data, p...)
+	return len(p), nil
+}
+
+// String returns the contents.
+func (b Buffer) String() string {
+	return fmt.Sprintf("%s", b.data)
+}
+
+// Flush does nothing.
+func (b *Buffer) Flush() {}
+
+// Plain satisfies no interfaces.
+type Plain struct{}
@@ -0,0 +1,7 @@
+package main
+
+import "ignoredfile/lib"
+
+func main() {
+	lib.Used()
+}
@@ -0,0 +1,3 @@
+module ignoredfile
+
+go 1.25.1
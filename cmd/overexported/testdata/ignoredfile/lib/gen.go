@@ -0,0 +1,15 @@
+//go:build ignore
+
+package main
+
+import (
+	"fmt"
+
+	"ignoredfile/lib"
+	"ignoredfile/yaml.v3"
+)
+
+func main() {
+	fmt.Println(lib.Names)
+	yaml.Marshal()
+}
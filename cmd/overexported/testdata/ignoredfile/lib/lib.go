@@ -0,0 +1,9 @@
+package lib
+
+//go:generate go run gen.go
+
+var Names = []string{"a", "b"}
+
+func Used() {}
+
+func Unused() {}
@@ -0,0 +1,4 @@
+// Package yaml's name doesn't match the last element of its import path.
+package yaml
+
+func Marshal() {}
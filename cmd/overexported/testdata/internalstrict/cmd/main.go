@@ -0,0 +1,11 @@
+package main
+
+import (
+	"internalstrict/internal/util"
+	"internalstrict/other"
+)
+
+func main() {
+	util.Helper()
+	other.Do()
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"testing"
+
+	"internalstrict/other"
+)
+
+func TestPublic(t *testing.T) {
+	other.PublicTestOnly()
+}
@@ -0,0 +1,3 @@
+module internalstrict
+
+go 1.25.1
@@ -0,0 +1,10 @@
+package util
+
+// Helper is used by main.
+func Helper() {}
+
+// TestOnly is only used by tests in another package.
+func TestOnly() {}
+
+// Unused is not used outside this package.
+func Unused() {}
@@ -0,0 +1,9 @@
+package other
+
+import "internalstrict/internal/util"
+
+// Do is used by main.
+func Do() { util.Helper() }
+
+// PublicTestOnly is only used by tests in another package.
+func PublicTestOnly() {}
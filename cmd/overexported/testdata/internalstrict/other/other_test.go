@@ -0,0 +1,12 @@
+package other
+
+import (
+	"testing"
+
+	"internalstrict/internal/util"
+)
+
+func TestDo(t *testing.T) {
+	util.TestOnly()
+	Do()
+}
@@ -0,0 +1,7 @@
+package main
+
+import "maplit/registry"
+
+func main() {
+	registry.Run()
+}
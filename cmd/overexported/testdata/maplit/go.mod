@@ -0,0 +1,3 @@
+module maplit
+
+go 1.25.1
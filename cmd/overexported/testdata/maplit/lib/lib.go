@@ -0,0 +1,13 @@
+package lib
+
+// InMap is only referenced from another package's map literal.
+func InMap() string { return "map" }
+
+// InSlice is only referenced from another package's slice literal.
+func InSlice() string { return "slice" }
+
+// InStruct is only referenced from another package's struct literal.
+func InStruct() string { return "struct" }
+
+// Unused is not referenced outside this package.
+func Unused() string { return "unused" }
@@ -0,0 +1,28 @@
+package registry
+
+import "maplit/lib"
+
+var handlers = map[string]func() string{
+	"map": lib.InMap,
+}
+
+var ordered = []func() string{
+	lib.InSlice,
+}
+
+var named = struct {
+	fn func() string
+}{
+	fn: lib.InStruct,
+}
+
+// Run calls every registered handler.
+func Run() {
+	for _, h := range handlers {
+		h()
+	}
+	for _, h := range ordered {
+		h()
+	}
+	named.fn()
+}
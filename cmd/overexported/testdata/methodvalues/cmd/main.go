@@ -0,0 +1,15 @@
+package main
+
+import "methodvalues/lib"
+
+var (
+	value func()
+	expr  func(lib.Thing)
+)
+
+func main() {
+	var t lib.Thing
+	t.Called()
+	value = t.ExternalValue
+	expr = lib.Thing.ExternalExpr
+}
@@ -0,0 +1,3 @@
+module methodvalues
+
+go 1.25.1
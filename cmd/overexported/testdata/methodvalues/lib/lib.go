@@ -0,0 +1,28 @@
+package lib
+
+// Thing is used by cmd.
+type Thing struct{}
+
+// Called is called by cmd.
+func (Thing) Called() {}
+
+// InternalValue is only referenced as a method value in this package and
+// never called.
+func (Thing) InternalValue() {}
+
+// ExternalValue is referenced as a method value by cmd and never called.
+func (Thing) ExternalValue() {}
+
+// ExternalExpr is referenced as a method expression by cmd and never called.
+func (Thing) ExternalExpr() {}
+
+// Never is never referenced.
+func (Thing) Never() {}
+
+// hooks holds method values that are never called.
+var hooks []func()
+
+func init() {
+	var t Thing
+	hooks = append(hooks, t.InternalValue)
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"mockonly/consumer"
+	"mockonly/mocks"
+	"mockonly/store"
+)
+
+func main() {
+	var m store.Memory
+	println(m.Get(""), m.Lookup(""), consumer.Load(&mocks.MockBackend{}))
+	_ = &mocks.MockStore{}
+	_ = &mocks.CacheMock{}
+}
@@ -0,0 +1,8 @@
+package consumer
+
+import "mockonly/store"
+
+// Load fetches from b.
+func Load(b store.Backend) string {
+	return b.Fetch()
+}
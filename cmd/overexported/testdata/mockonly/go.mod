@@ -0,0 +1,3 @@
+module mockonly
+
+go 1.25.1
@@ -0,0 +1,29 @@
+package store
+
+// Store is only referenced outside this package by its mockgen mock.
+type Store interface {
+	Get(key string) string
+}
+
+// Cache is only referenced outside this package by its moq mock.
+type Cache interface {
+	Lookup(key string) string
+}
+
+// Backend is referenced by consumer as well as by its mock.
+type Backend interface {
+	Fetch() string
+}
+
+// Memory implements Store and Cache.
+type Memory struct{}
+
+// Get implements Store.
+func (Memory) Get(string) string {
+	return ""
+}
+
+// Lookup implements Cache.
+func (Memory) Lookup(string) string {
+	return ""
+}
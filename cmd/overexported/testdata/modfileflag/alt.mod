@@ -0,0 +1,3 @@
+module modfileflag
+
+go 1.25.1
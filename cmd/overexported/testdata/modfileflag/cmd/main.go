@@ -0,0 +1,7 @@
+package main
+
+import "modfileflag/lib"
+
+func main() {
+	lib.Used()
+}
@@ -0,0 +1,7 @@
+package lib
+
+// Used is used externally.
+func Used() {}
+
+// Unused is not used externally.
+func Unused() {}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"fmt"
+
+	"nameclash/lib"
+)
+
+func main() {
+	var s lib.Store
+	fmt.Println(lib.Get())
+	_ = s
+}
@@ -0,0 +1,3 @@
+module nameclash
+
+go 1.25.1
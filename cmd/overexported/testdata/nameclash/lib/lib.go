@@ -0,0 +1,9 @@
+package lib
+
+type Store struct{}
+
+func (Store) Get() string { return "method" }
+
+func (Store) Put() {}
+
+func Get() string { return "func" }
@@ -0,0 +1,7 @@
+package main
+
+import "orphans/used"
+
+func main() {
+	used.Used()
+}
@@ -0,0 +1,4 @@
+package orphan
+
+// Abandoned is in a package nothing imports.
+func Abandoned() {}
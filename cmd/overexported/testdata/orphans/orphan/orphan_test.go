@@ -0,0 +1,11 @@
+package orphan_test
+
+import (
+	"testing"
+
+	"orphans/orphan"
+)
+
+func TestAbandoned(t *testing.T) {
+	orphan.Abandoned()
+}
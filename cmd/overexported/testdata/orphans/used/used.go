@@ -0,0 +1,4 @@
+package used
+
+// Used is used by main.
+func Used() {}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+
+	"panictypes/lib"
+)
+
+func main() {
+	defer func() {
+		switch r := recover().(type) {
+		case lib.RecoveredType:
+			fmt.Println("recovered")
+		case *lib.PointerRecoveredType:
+			fmt.Println("recovered pointer")
+		default:
+			panic(r)
+		}
+	}()
+	lib.Boom("value")
+}
@@ -0,0 +1,3 @@
+module panictypes
+
+go 1.25.1
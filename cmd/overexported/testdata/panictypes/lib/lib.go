@@ -0,0 +1,21 @@
+package lib
+
+type RecoveredType struct {
+	Reason string
+}
+
+type PointerRecoveredType struct{}
+
+type UnrecoveredType struct{}
+
+func Boom(reason string) {
+	switch reason {
+	case "pointer":
+		panic(&PointerRecoveredType{})
+	case "unrecovered":
+		panic(UnrecoveredType{})
+	}
+	panic(RecoveredType{Reason: reason})
+}
+
+type UnusedType struct{}
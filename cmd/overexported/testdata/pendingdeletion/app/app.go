@@ -0,0 +1,8 @@
+package app
+
+import "pendingdeletion/lib"
+
+// Run uses lib.
+func Run() {
+	lib.Shared()
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"pendingdeletion/app"
+	"pendingdeletion/legacy"
+)
+
+func main() {
+	app.Run()
+	legacy.Run()
+}
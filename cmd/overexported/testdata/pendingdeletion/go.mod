@@ -0,0 +1,3 @@
+module pendingdeletion
+
+go 1.25.1
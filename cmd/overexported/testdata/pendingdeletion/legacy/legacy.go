@@ -0,0 +1,15 @@
+package legacy
+
+import "pendingdeletion/lib"
+
+// Run uses lib.
+func Run() {
+	lib.LegacyOnly()
+	lib.Shared()
+	helper()
+}
+
+// Helper is over-exported, but legacy is going away.
+func Helper() {}
+
+func helper() { Helper() }
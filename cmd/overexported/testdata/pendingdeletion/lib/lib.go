@@ -0,0 +1,10 @@
+package lib
+
+// LegacyOnly is used only by legacy.
+func LegacyOnly() {}
+
+// Shared is used by legacy and app.
+func Shared() {}
+
+// Unused is not used externally.
+func Unused() {}
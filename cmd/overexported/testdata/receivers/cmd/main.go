@@ -0,0 +1,7 @@
+package main
+
+import "receivers"
+
+func main() {
+	receivers.New().Used()
+}
@@ -0,0 +1,15 @@
+package receivers
+
+// New returns a value of an unexported type.
+func New() *internal {
+	return &internal{}
+}
+
+type internal struct{}
+
+// Used is called externally through the value returned by New.
+func (t *internal) Used() {}
+
+// Export is an exported method on an unexported type that is never used
+// externally.
+func (t *internal) Export() {}
@@ -0,0 +1,7 @@
+package a
+
+// AUsed is used by cmd.
+func AUsed() {}
+
+// AUnused is not used externally.
+func AUnused() {}
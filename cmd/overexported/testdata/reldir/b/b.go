@@ -0,0 +1,7 @@
+package b
+
+// BUsed is used by cmd.
+func BUsed() {}
+
+// BUnused is not used externally.
+func BUnused() {}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"reldir/a"
+	"reldir/b"
+)
+
+func main() {
+	a.AUsed()
+	b.BUsed()
+}
@@ -0,0 +1,3 @@
+module reldir
+
+go 1.25.1
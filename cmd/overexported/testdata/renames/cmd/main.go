@@ -0,0 +1,8 @@
+package main
+
+import "renames/lib"
+
+func main() {
+	_ = lib.Used{}
+	_ = lib.Person{}
+}
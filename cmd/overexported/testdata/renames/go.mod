@@ -0,0 +1,3 @@
+module renames
+
+go 1.25.1
@@ -0,0 +1,70 @@
+package lib
+
+// URL is not used externally.
+const URL = "https://example.com"
+
+// ID is not used externally.
+var ID = 1
+
+// API is not used externally.
+type API struct{}
+
+// OAuth2Token is not used externally.
+type OAuth2Token struct{}
+
+// HTTPClient is not used externally.
+type HTTPClient struct{}
+
+// IDsByName is not used externally.
+func IDsByName() {}
+
+// FOOBar is not used externally.
+func FOOBar() {}
+
+// IDE is not used externally.
+type IDE struct{}
+
+// IPv4 is not used externally.
+type IPv4 struct{}
+
+// IOStream is not used externally.
+type IOStream struct{}
+
+// Type is not used externally.
+func Type() {}
+
+// Used is used externally.
+type Used struct{}
+
+// JSONValue is not used externally.
+func (Used) JSONValue() {}
+
+// String is not used externally.
+func (Used) String() string { return "" }
+
+// Person is used externally.
+type Person struct {
+	name string
+}
+
+// Name is not used externally.
+func (p Person) Name() string { return p.name }
+
+// Age is not used externally.
+func (Person) Age() int { return 0 }
+
+// Describe is not used externally.
+func (Person) Describe() {}
+
+func (Person) describe() {}
+
+// New is not used externally.
+func New() {}
+
+// String is not used externally.
+type String struct{}
+
+// Clash is not used externally.
+func Clash() {}
+
+func clash() {}
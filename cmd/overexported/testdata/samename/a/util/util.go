@@ -0,0 +1,11 @@
+package util
+
+// Helper is not used externally.
+func Helper() string {
+	return "a"
+}
+
+// Used is used externally.
+func Used() string {
+	return Helper()
+}
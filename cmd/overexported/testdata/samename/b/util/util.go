@@ -0,0 +1,11 @@
+package util
+
+// Helper is not used externally.
+func Helper() string {
+	return "b"
+}
+
+// Used is used externally.
+func Used() string {
+	return Helper()
+}
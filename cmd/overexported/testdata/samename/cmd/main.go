@@ -0,0 +1,10 @@
+package main
+
+import (
+	autil "samename/a/util"
+	butil "samename/b/util"
+)
+
+func main() {
+	println(autil.Used(), butil.Used())
+}
@@ -0,0 +1,8 @@
+package main
+
+import "stringer"
+
+func main() {
+	var c stringer.Color = stringer.Red
+	_ = c
+}
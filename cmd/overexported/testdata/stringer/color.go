@@ -0,0 +1,10 @@
+package stringer
+
+// Color is used externally.
+type Color int
+
+// Colors.
+const (
+	Red Color = iota
+	Green
+)
@@ -0,0 +1,3 @@
+module stringer
+
+go 1.25.1
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"os"
+	"text/template"
+
+	"templates"
+)
+
+func main() {
+	tmpl := template.Must(template.New("page").Parse("<h1>{{.Heading}}</h1>"))
+	_ = tmpl.Execute(os.Stdout, templates.Page{Title: "hello"})
+	_ = templates.NotRendered{}
+}
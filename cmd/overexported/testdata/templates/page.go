@@ -0,0 +1,33 @@
+package templates
+
+// Page is rendered by a template.
+type Page struct {
+	Title string
+	Nav   *Nav
+}
+
+// Heading is called by the template.
+func (p Page) Heading() string {
+	return p.Title
+}
+
+// Footer is not called by the template, but a template could call it.
+func (p Page) Footer() string {
+	return ""
+}
+
+// Nav is reachable from Page through a field.
+type Nav struct{}
+
+// Links is not called by the template, but a template could call it.
+func (n *Nav) Links() []string {
+	return nil
+}
+
+// NotRendered is never passed to a template.
+type NotRendered struct{}
+
+// Heading is not reachable from a template.
+func (n NotRendered) Heading() string {
+	return ""
+}
@@ -0,0 +1,4 @@
+package bar
+
+// Bar is used by main.
+func Bar() bool { return true }
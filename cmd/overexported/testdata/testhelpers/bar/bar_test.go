@@ -0,0 +1,12 @@
+package bar_test
+
+import (
+	"testing"
+
+	"testhelpers/bar"
+	"testhelpers/testutil"
+)
+
+func TestBar(t *testing.T) {
+	testutil.AssertOK(t, bar.Bar())
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"fmt"
+
+	"testhelpers/bar"
+	"testhelpers/foo"
+)
+
+func main() {
+	fmt.Println(foo.Foo(), bar.Bar())
+}
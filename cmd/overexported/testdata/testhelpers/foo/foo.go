@@ -0,0 +1,4 @@
+package foo
+
+// Foo is used by main.
+func Foo() bool { return true }
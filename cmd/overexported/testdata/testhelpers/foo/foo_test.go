@@ -0,0 +1,12 @@
+package foo_test
+
+import (
+	"testing"
+
+	"testhelpers/foo"
+	"testhelpers/testutil"
+)
+
+func TestFoo(t *testing.T) {
+	testutil.AssertOK(t, foo.Foo())
+}
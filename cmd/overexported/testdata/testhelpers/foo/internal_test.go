@@ -0,0 +1,13 @@
+package foo
+
+import (
+	"testing"
+
+	"testhelpers/testutil"
+)
+
+func TestInternal(t *testing.T) {
+	if !testutil.UsedByInternalTest() {
+		t.Fatal("not ok")
+	}
+}
@@ -0,0 +1,3 @@
+module testhelpers
+
+go 1.25.1
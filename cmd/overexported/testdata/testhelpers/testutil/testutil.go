@@ -0,0 +1,17 @@
+package testutil
+
+import "testing"
+
+// AssertOK is used by two external test packages.
+func AssertOK(t *testing.T, ok bool) {
+	t.Helper()
+	if !ok {
+		t.Fatal("not ok")
+	}
+}
+
+// UsedByInternalTest is used by an in-package test file of another package.
+func UsedByInternalTest() bool { return true }
+
+// Unused is not used outside this package.
+func Unused() {}
@@ -0,0 +1,7 @@
+package main
+
+import "toolsfile/lib"
+
+func main() {
+	lib.Used()
+}
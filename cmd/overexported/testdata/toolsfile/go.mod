@@ -0,0 +1,3 @@
+module toolsfile
+
+go 1.25.1
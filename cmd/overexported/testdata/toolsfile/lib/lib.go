@@ -0,0 +1,7 @@
+package lib
+
+// Used is used by cmd.
+func Used() {}
+
+// PinnedOnly is only referenced by tools.go.
+func PinnedOnly() {}
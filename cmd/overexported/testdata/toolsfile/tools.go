@@ -0,0 +1,9 @@
+//go:build tools
+
+package tools
+
+import (
+	"toolsfile/lib"
+)
+
+var _ = lib.PinnedOnly
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"transitive/pkgb"
+)
+
+func main() {
+	fmt.Println(pkgb.Run())
+}
@@ -0,0 +1,10 @@
+package pkga
+
+// Bar is only used by pkgb.Baz, which nothing calls.
+func Bar() string { return "bar" }
+
+// BarType is only used by pkgb.Baz, which nothing calls.
+type BarType struct{}
+
+// Used is used by main.
+func Used() string { return "used" }
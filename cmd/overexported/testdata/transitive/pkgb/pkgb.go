@@ -0,0 +1,13 @@
+package pkgb
+
+import "transitive/pkga"
+
+// Baz is over-exported and unreachable.
+func Baz() string {
+	var t pkga.BarType
+	_ = t
+	return pkga.Bar()
+}
+
+// Run is used by main.
+func Run() string { return pkga.Used() }
@@ -0,0 +1,8 @@
+package main
+
+import "undocumented/lib"
+
+func main() {
+	var t lib.DocumentedType
+	_ = t
+}
@@ -0,0 +1,3 @@
+module undocumented
+
+go 1.25.1
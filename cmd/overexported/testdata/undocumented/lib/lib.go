@@ -0,0 +1,21 @@
+package lib
+
+// Documented has a doc comment.
+func Documented() {}
+
+func Undocumented() {}
+
+// DocumentedType has a doc comment.
+type DocumentedType struct{}
+
+// DocumentedMethod has a doc comment.
+func (*DocumentedType) DocumentedMethod() {}
+
+func (*DocumentedType) UndocumentedMethod() {}
+
+// Grouped constants. This comment doesn't document the members.
+const (
+	// DocumentedConst has a doc comment.
+	DocumentedConst   = 1
+	UndocumentedConst = 2
+)
@@ -0,0 +1,9 @@
+package app
+
+import "usedonlyby/lib"
+
+// Run uses lib.
+func Run() {
+	lib.Shared()
+	lib.AppOnly()
+}
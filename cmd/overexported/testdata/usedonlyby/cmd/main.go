@@ -0,0 +1,13 @@
+package main
+
+import (
+	"usedonlyby/app"
+	"usedonlyby/legacy/old"
+	"usedonlyby/legacy/older"
+)
+
+func main() {
+	app.Run()
+	old.Run()
+	older.Run()
+}
@@ -0,0 +1,3 @@
+module usedonlyby
+
+go 1.25.1
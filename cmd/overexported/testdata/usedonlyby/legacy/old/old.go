@@ -0,0 +1,9 @@
+package old
+
+import "usedonlyby/lib"
+
+// Run uses lib.
+func Run() {
+	lib.LegacyOnly()
+	lib.Shared()
+}
@@ -0,0 +1,8 @@
+package older
+
+import "usedonlyby/lib"
+
+// Run uses lib.
+func Run() {
+	lib.LegacyOnly()
+}
@@ -0,0 +1,13 @@
+package lib
+
+// LegacyOnly is used only by packages under legacy.
+func LegacyOnly() {}
+
+// Shared is used by legacy and app.
+func Shared() {}
+
+// AppOnly is used only by app.
+func AppOnly() {}
+
+// Unused is not used externally.
+func Unused() {}
@@ -0,0 +1,3 @@
+module moda
+
+go 1.25.1
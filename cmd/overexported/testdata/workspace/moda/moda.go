@@ -0,0 +1,7 @@
+package moda
+
+// CrossModuleUsed is used by modb.
+func CrossModuleUsed() {}
+
+// Unused is not used by any module.
+func Unused() {}
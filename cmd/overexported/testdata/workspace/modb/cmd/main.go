@@ -0,0 +1,7 @@
+package main
+
+import "moda"
+
+func main() {
+	moda.CrossModuleUsed()
+}
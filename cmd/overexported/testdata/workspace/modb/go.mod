@@ -0,0 +1,3 @@
+module modb
+
+go 1.25.1
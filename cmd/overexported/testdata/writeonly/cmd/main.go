@@ -0,0 +1,14 @@
+package main
+
+import (
+	"fmt"
+
+	"writeonly/lib"
+)
+
+func main() {
+	lib.WriteOnly = "custom"
+	fmt.Println(lib.ReadOnly)
+	lib.ReadWrite = lib.ReadWrite + 1
+	lib.Print()
+}
@@ -0,0 +1,3 @@
+module writeonly
+
+go 1.25.1
@@ -0,0 +1,17 @@
+package lib
+
+import "fmt"
+
+// WriteOnly is assigned externally but never read there.
+var WriteOnly = "default"
+
+// ReadOnly is read externally but never assigned there.
+var ReadOnly = 1
+
+// ReadWrite is both read and assigned externally.
+var ReadWrite = 1
+
+// Print is used externally.
+func Print() {
+	fmt.Println(WriteOnly, ReadOnly, ReadWrite)
+}
@@ -0,0 +1,119 @@
+package overexported
+
+import (
+	"fmt"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"maps"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// knownOS returns the GOOS values, which are set by the build configuration
+// rather than by -tags.
+func knownOS() []string {
+	return []string{
+		"aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos",
+		"ios", "js", "linux", "nacl", "netbsd", "openbsd", "plan9", "solaris",
+		"wasip1", "windows", "zos",
+	}
+}
+
+// knownArch returns the GOARCH values, which are set by the build
+// configuration rather than by -tags.
+func knownArch() []string {
+	return []string{
+		"386", "amd64", "amd64p32", "arm", "armbe", "arm64", "arm64be",
+		"loong64", "mips", "mipsle", "mips64", "mips64le", "mips64p32",
+		"mips64p32le", "ppc", "ppc64", "ppc64le", "riscv", "riscv64", "s390",
+		"s390x", "sparc", "sparc64", "wasm",
+	}
+}
+
+// discoverBuildTags returns the sorted custom build tags that files of the
+// packages matching patterns require but that aren't set by opts. Only tags
+// that a constraint requires, rather than forbids, are returned. GOOS, GOARCH
+// and compiler tags are left out, as are "ignore" and "tools", which mark
+// files that aren't meant to be built with the package.
+func discoverBuildTags(opts Options, patterns []string) ([]string, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:       packages.NeedName | packages.NeedFiles,
+		Tests:      opts.Test,
+		Dir:        opts.Dir,
+		BuildFlags: buildFlags(opts),
+	}, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("discover build tags: %w", err)
+	}
+	tags := make(map[string]bool)
+	seen := make(map[string]bool)
+	fset := token.NewFileSet()
+	for _, pkg := range pkgs {
+		for _, filename := range pkg.IgnoredFiles {
+			if seen[filename] || !strings.HasSuffix(filename, ".go") {
+				continue
+			}
+			seen[filename] = true
+			collectFileRequiredTags(fset, filename, tags)
+		}
+	}
+	for _, tag := range opts.Tags {
+		delete(tags, tag)
+	}
+	return slices.Sorted(maps.Keys(tags)), nil
+}
+
+// collectFileRequiredTags adds the custom tags that the build constraints of
+// filename require to tags. Files that can't be parsed are skipped.
+func collectFileRequiredTags(fset *token.FileSet, filename string, tags map[string]bool) {
+	file, err := parser.ParseFile(fset, filename, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return
+	}
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, c := range group.List {
+			expr, err := constraint.Parse(c.Text)
+			if err == nil && constraint.IsGoBuild(c.Text) {
+				collectRequiredTags(expr, false, tags)
+			}
+		}
+	}
+}
+
+// collectRequiredTags adds the custom tags that expr requires to tags. negated
+// is set when expr is under an odd number of "!" operators.
+func collectRequiredTags(expr constraint.Expr, negated bool, tags map[string]bool) {
+	switch expr := expr.(type) {
+	case *constraint.NotExpr:
+		collectRequiredTags(expr.X, !negated, tags)
+	case *constraint.AndExpr:
+		collectRequiredTags(expr.X, negated, tags)
+		collectRequiredTags(expr.Y, negated, tags)
+	case *constraint.OrExpr:
+		collectRequiredTags(expr.X, negated, tags)
+		collectRequiredTags(expr.Y, negated, tags)
+	case *constraint.TagExpr:
+		if !negated && isCustomTag(expr.Tag) {
+			tags[expr.Tag] = true
+		}
+	}
+}
+
+// isCustomTag reports whether tag is set with -tags rather than by the build
+// configuration or toolchain.
+func isCustomTag(tag string) bool {
+	switch tag {
+	case "cgo", "gc", "gccgo", "unix", "ignore", "tools":
+		return false
+	}
+	return !strings.HasPrefix(tag, "go1.") &&
+		!strings.HasPrefix(tag, "goexperiment.") &&
+		!slices.Contains(knownOS(), tag) &&
+		!slices.Contains(knownArch(), tag)
+}
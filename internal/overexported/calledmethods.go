@@ -0,0 +1,112 @@
+package overexported
+
+import (
+	"cmp"
+	"go/types"
+	"slices"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// CalledMethods lists which methods of an exported type other packages use.
+type CalledMethods struct {
+	// Type is the type in "pkgpath.Name" form.
+	Type string `json:"type"`
+	// Methods are the exported methods declared on the type, sorted.
+	Methods []string `json:"methods"`
+	// CalledByExternal are the Methods used outside the type's package,
+	// sorted.
+	CalledByExternal []string `json:"calledByExternal"`
+}
+
+// findCalledMethods returns, for each exported type of the target packages
+// with exported methods that a function or method in another package takes
+// as a parameter, directly or by pointer, the methods other packages use.
+// When they are a subset of the type's methods, the parameter could be a
+// smaller interface.
+func findCalledMethods(allPkgs []*packages.Package, targetPaths map[string]bool, used usage) []CalledMethods {
+	var result []CalledMethods
+	for tn := range externalParamTypes(allPkgs, targetPaths) {
+		entry, ok := calledMethodsEntry(tn, used)
+		if ok {
+			result = append(result, entry)
+		}
+	}
+	slices.SortFunc(result, func(a, b CalledMethods) int {
+		return cmp.Compare(a.Type, b.Type)
+	})
+	return result
+}
+
+// externalParamTypes returns the exported types of the target packages that
+// a function or method in another package takes as a parameter, directly or
+// by pointer. Instantiated types are recorded as their generic origin.
+func externalParamTypes(allPkgs []*packages.Package, targetPaths map[string]bool) map[*types.TypeName]bool {
+	params := make(map[*types.TypeName]bool)
+	for _, pkg := range allPkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		pkgPath := vendorlessPath(pkg.PkgPath)
+		for _, obj := range pkg.TypesInfo.Defs {
+			fn, ok := obj.(*types.Func)
+			if !ok {
+				continue
+			}
+			for param := range fn.Signature().Params().Variables() {
+				if tn := externalParamType(param.Type(), pkgPath, targetPaths); tn != nil {
+					params[tn] = true
+				}
+			}
+		}
+	}
+	return params
+}
+
+// externalParamType returns the exported target type a parameter of type t
+// in package pkgPath refers to, or nil if t is not one from another package.
+func externalParamType(t types.Type, pkgPath string, targetPaths map[string]bool) *types.TypeName {
+	t = types.Unalias(t)
+	if ptr, isPtr := t.(*types.Pointer); isPtr {
+		t = types.Unalias(ptr.Elem())
+	}
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || !named.Obj().Exported() {
+		return nil
+	}
+	typePkg := vendorlessPath(named.Obj().Pkg().Path())
+	if !targetPaths[typePkg] || typePkg == pkgPath {
+		return nil
+	}
+	return named.Origin().Obj()
+}
+
+// calledMethodsEntry returns the CalledMethods for tn. It returns false for
+// interfaces and types without exported methods.
+func calledMethodsEntry(tn *types.TypeName, used usage) (CalledMethods, bool) {
+	named, ok := tn.Type().(*types.Named)
+	if !ok || types.IsInterface(named) {
+		return CalledMethods{}, false
+	}
+	entry := CalledMethods{
+		Type:             tn.Pkg().Path() + "." + tn.Name(),
+		Methods:          []string{},
+		CalledByExternal: []string{},
+	}
+	for method := range named.Methods() {
+		if !method.Exported() {
+			continue
+		}
+		entry.Methods = append(entry.Methods, method.Name())
+		key := usageKey{pkgPath: tn.Pkg().Path(), typeName: tn.Name(), name: method.Name()}
+		if len(used[key]) > 0 {
+			entry.CalledByExternal = append(entry.CalledByExternal, method.Name())
+		}
+	}
+	if len(entry.Methods) == 0 {
+		return CalledMethods{}, false
+	}
+	slices.Sort(entry.Methods)
+	slices.Sort(entry.CalledByExternal)
+	return entry, true
+}
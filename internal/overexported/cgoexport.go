@@ -0,0 +1,38 @@
+package overexported
+
+import (
+	"go/ast"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// scanCgoExports is a DirectiveScanner that returns the keys of functions
+// with a "//export" directive. They are called from C through cgo-generated
+// code, which the analysis doesn't see, and cgo requires the exported name
+// to match the Go name.
+func scanCgoExports(pkg *packages.Package, file *ast.File) []string {
+	var keys []string
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if ok && fn.Recv == nil && hasCgoExport(fn) {
+			keys = append(keys, pkg.PkgPath+"."+fn.Name.Name)
+		}
+	}
+	return keys
+}
+
+// hasCgoExport reports whether fn's doc comment has an "//export" directive
+// for fn.
+func hasCgoExport(fn *ast.FuncDecl) bool {
+	if fn.Doc == nil {
+		return false
+	}
+	for _, c := range fn.Doc.List {
+		name, ok := strings.CutPrefix(c.Text, "//export ")
+		if ok && strings.TrimSpace(name) == fn.Name.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package overexported
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// DirectiveScanner inspects a file for comment directives or other markers
+// that reference exported identifiers without a Go reference the type checker
+// can see, such as custom codegen markers or dependency injection
+// annotations. pkg is the package file belongs to, so that a marker on a
+// declaration can name it. It returns the keys, in "pkgpath.Name" or
+// "pkgpath.Type.Method" form, of the identifiers to treat as used.
+type DirectiveScanner func(pkg *packages.Package, file *ast.File) []string
+
+// builtinDirectiveScanners returns the scanners that run before
+// Options.DirectiveScanners.
+func builtinDirectiveScanners() []DirectiveScanner {
+	return []DirectiveScanner{scanCgoExports}
+}
+
+// addDirectiveUsage runs the built-in scanners and opts.DirectiveScanners
+// over every file in allPkgs that isn't in ignoredFiles and marks the exports
+// they name as used by the scanned file's package. Exports named from their
+// own package are marked as used by an unknown package instead, since the
+// marker stands for a user the analysis can't see.
+func addDirectiveUsage(
+	opts Options,
+	allPkgs []*packages.Package,
+	exports map[string]Export,
+	ignoredFiles map[string]bool,
+	used usage,
+) {
+	scanners := append(builtinDirectiveScanners(), opts.DirectiveScanners...)
+	for _, pkg := range allPkgs {
+		user := normalizePkgPath(pkg.PkgPath, opts)
+		for _, file := range pkg.Syntax {
+			if ignoredFiles[pkg.Fset.File(file.Pos()).Name()] {
+				continue
+			}
+			for _, scan := range scanners {
+				for _, key := range scan(pkg, file) {
+					exp, ok := exports[key]
+					if !ok {
+						continue
+					}
+					if exp.PkgPath == user {
+						used.add(exportUsageKey(exp), "")
+						continue
+					}
+					used.add(exportUsageKey(exp), user)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package overexported
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"strconv"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// findIgnoredFileRefs returns the keys of target package members referenced
+// from files excluded by a "//go:build ignore" constraint. These are usually
+// generators or standalone programs that live alongside a package and are run
+// with "go run". They aren't part of the build, so their references don't
+// count as usage, but unexporting what they use would break them.
+func findIgnoredFileRefs(allPkgs []*packages.Package, targetPaths map[string]bool) map[string]bool {
+	targetNames := make(map[string]string)
+	for _, pkg := range allPkgs {
+		if targetPaths[pkg.PkgPath] {
+			targetNames[pkg.PkgPath] = pkg.Name
+		}
+	}
+	refs := make(map[string]bool)
+	seen := make(map[string]bool)
+	fset := token.NewFileSet()
+	for _, pkg := range allPkgs {
+		for _, filename := range pkg.IgnoredFiles {
+			if seen[filename] {
+				continue
+			}
+			seen[filename] = true
+			file, err := parser.ParseFile(fset, filename, nil, parser.ParseComments|parser.SkipObjectResolution)
+			if err != nil || !requiresOnlyTag(file, "ignore") {
+				continue
+			}
+			collectSelectorRefs(file, targetNames, refs)
+		}
+	}
+	return refs
+}
+
+// findToolsFiles returns the names of files in pkgs built only with the
+// "tools" tag. These follow the tools.go convention of importing tool
+// packages to pin them in go.mod, so their references aren't real usage.
+// They are only loaded when the "tools" tag is set.
+func findToolsFiles(pkgs []*packages.Package) map[string]bool {
+	toolsFiles := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			if requiresOnlyTag(file, "tools") {
+				toolsFiles[pkg.Fset.File(file.Pos()).Name()] = true
+			}
+		}
+	}
+	return toolsFiles
+}
+
+// requiresOnlyTag reports whether file's //go:build line excludes it only
+// because of tag.
+func requiresOnlyTag(file *ast.File, tag string) bool {
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, c := range group.List {
+			if !constraint.IsGoBuild(c.Text) {
+				continue
+			}
+			expr, err := constraint.Parse(c.Text)
+			if err != nil {
+				return false
+			}
+			return expr.Eval(func(string) bool { return true }) &&
+				!expr.Eval(func(t string) bool { return t != tag })
+		}
+	}
+	return false
+}
+
+// collectSelectorRefs adds the keys of "pkg.Name" selectors in file that refer
+// to imported target packages. targetNames maps the path of each target
+// package to its name.
+func collectSelectorRefs(file *ast.File, targetNames map[string]string, refs map[string]bool) {
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		pkgPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name, ok := targetNames[pkgPath]
+		if !ok {
+			continue
+		}
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = pkgPath
+	}
+	if len(imports) == 0 {
+		return
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if pkgPath, ok := imports[ident.Name]; ok && token.IsExported(sel.Sel.Name) {
+			refs[pkgPath+"."+sel.Sel.Name] = true
+		}
+		return true
+	})
+}
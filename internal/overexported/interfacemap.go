@@ -0,0 +1,78 @@
+package overexported
+
+import (
+	"go/types"
+	"slices"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// findInterfaceMap returns, for each exported non-interface type in the
+// target packages, the named interfaces it satisfies by value or by pointer.
+// Both are keyed and listed in "pkgpath.Name" form. Interfaces are taken from
+// every loaded package and its dependencies; those in other packages must be
+// exported. Interfaces without methods and generic interfaces are left out.
+func findInterfaceMap(allPkgs []*packages.Package, targetPaths map[string]bool) map[string][]string {
+	var ifaces []*types.TypeName
+	var targets []*types.TypeName
+	packages.Visit(allPkgs, nil, func(pkg *packages.Package) {
+		if pkg.Types == nil {
+			return
+		}
+		for _, tn := range declaredTypes(pkg.Types) {
+			iface, isIface := tn.Type().Underlying().(*types.Interface)
+			switch {
+			case isIface && iface.NumMethods() > 0:
+				ifaces = append(ifaces, tn)
+			case !isIface && tn.Exported() && targetPaths[pkg.PkgPath]:
+				targets = append(targets, tn)
+			}
+		}
+	})
+
+	ifaceMap := make(map[string][]string)
+	for _, tn := range targets {
+		satisfied := satisfiedInterfaces(tn, ifaces)
+		if len(satisfied) > 0 {
+			ifaceMap[tn.Pkg().Path()+"."+tn.Name()] = satisfied
+		}
+	}
+	return ifaceMap
+}
+
+// declaredTypes returns the package-level named types of pkg that are
+// neither aliases nor generic.
+func declaredTypes(pkg *types.Package) []*types.TypeName {
+	var declared []*types.TypeName
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || tn.IsAlias() {
+			continue
+		}
+		named, ok := tn.Type().(*types.Named)
+		if !ok || named.TypeParams().Len() > 0 {
+			continue
+		}
+		declared = append(declared, tn)
+	}
+	return declared
+}
+
+// satisfiedInterfaces returns the sorted "pkgpath.Name" of the interfaces in
+// ifaces that tn satisfies by value or by pointer. Unexported interfaces only
+// count in tn's own package.
+func satisfiedInterfaces(tn *types.TypeName, ifaces []*types.TypeName) []string {
+	var satisfied []string
+	for _, ifaceName := range ifaces {
+		if ifaceName.Pkg() != tn.Pkg() && !ifaceName.Exported() {
+			continue
+		}
+		iface := ifaceName.Type().Underlying().(*types.Interface)
+		if types.Implements(tn.Type(), iface) || types.Implements(types.NewPointer(tn.Type()), iface) {
+			satisfied = append(satisfied, ifaceName.Pkg().Path()+"."+ifaceName.Name())
+		}
+	}
+	slices.Sort(satisfied)
+	return slices.Compact(satisfied)
+}
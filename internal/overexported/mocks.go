@@ -0,0 +1,81 @@
+package overexported
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// mockGenerators returns the names generated mock files give their generator
+// in the "Code generated by" comment.
+func mockGenerators() []string {
+	return []string{"MockGen", "mockgen", "moq", "mockery"}
+}
+
+// findMockFiles returns the names of generated files in pkgs that were
+// written by a mock generator. isGenerated is as for findGeneratedFiles.
+func findMockFiles(pkgs []*packages.Package, isGenerated func(string, *ast.File) bool) map[string]bool {
+	mockFiles := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			filename := pkg.Fset.File(file.Pos()).Name()
+			if isMockFile(isGenerated, filename, file) {
+				mockFiles[filename] = true
+			}
+		}
+	}
+	return mockFiles
+}
+
+// isMockFile reports whether file is a generated file written by a mock
+// generator.
+func isMockFile(isGenerated func(string, *ast.File) bool, filename string, file *ast.File) bool {
+	if !isGeneratedFile(isGenerated, filename, file) {
+		return false
+	}
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, c := range group.List {
+			generator, ok := strings.CutPrefix(c.Text, "// Code generated by ")
+			if !ok {
+				continue
+			}
+			for _, name := range mockGenerators() {
+				if strings.HasPrefix(generator, name) || strings.HasPrefix(generator, `"`+name) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// findMockOnlyInterfaces returns the keys of exported interfaces that are
+// used outside their package, but not once references from mock files are
+// left out. Such an interface may exist only so that it can be mocked.
+func findMockOnlyInterfaces(
+	allPkgs []*packages.Package,
+	exports map[string]Export,
+	used, usedWithoutMocks usage,
+) map[string]bool {
+	typesPkgs := typesPackages(allPkgs)
+	mockOnly := make(map[string]bool)
+	for key, exp := range exports {
+		if exp.Kind != "type" || typesPkgs[exp.PkgPath] == nil {
+			continue
+		}
+		k := exportUsageKey(exp)
+		if len(used[k]) == 0 || len(usedWithoutMocks[k]) > 0 {
+			continue
+		}
+		tn, ok := typesPkgs[exp.PkgPath].Scope().Lookup(exp.Name).(*types.TypeName)
+		if ok && types.IsInterface(tn.Type()) {
+			mockOnly[key] = true
+		}
+	}
+	return mockOnly
+}
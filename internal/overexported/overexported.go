@@ -1,13 +1,23 @@
 package overexported
 
 import (
+	"cmp"
 	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
+	"iter"
+	"maps"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
+	"golang.org/x/tools/go/callgraph"
 	"golang.org/x/tools/go/callgraph/rta"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/ssa"
@@ -21,25 +31,249 @@ type Position struct {
 	Col  int    `json:"col"`
 }
 
+// String returns the position in "file:line:col" form.
+func (p Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Col)
+}
+
 // Export represents an exported symbol that can be unexported.
 type Export struct {
+	// Key is PkgPath and Name joined by ".", the form used by Options.Keep.
+	// Run leaves it empty; callers may fill it in with SetKeys.
+	Key      string   `json:"key,omitempty"`
 	Name     string   `json:"name"`
 	Kind     string   `json:"kind"`
 	Position Position `json:"position"`
 	PkgPath  string   `json:"package"`
+	// UnreachableReceiver is set for exported methods on unexported types.
+	// These can't be named from outside the package, so they are almost
+	// always safe to unexport.
+	UnreachableReceiver bool `json:"unreachableReceiver,omitempty"`
+	// Confidence is a score from 0 to 1 estimating how likely it is that the
+	// identifier can be unexported without breaking anything the analysis
+	// can't see. It starts from the kind: 1 for functions and constants,
+	// which can only be reached by name; 0.9 for variables, which linker
+	// flags can set; 0.8 for types, which reflection can reach; and 0.6 for
+	// methods, which may satisfy an interface only checked at run time, or
+	// 0.9 when UnreachableReceiver is set. It is then halved for each of a
+	// well-known dynamically checked method name such as String or
+	// MarshalJSON, MaybeTemplateAccessed, MaybeUsedByIgnoredFile and
+	// UsedByGenerated, multiplied by 0.8 when OnlyUsedByUnreachable is set and
+	// by 0.7 when any of ExamplesOnly, MockOnlyInterface, WillBeOrphaned,
+	// TestCoverageGap or InternalStrict is set, and rounded to two decimal
+	// places. It is only set for reported exports, so it is omitted from
+	// inventory entries with any other status.
+	Confidence float64 `json:"confidence,omitempty"`
+	// ExamplesOnly is set for exports whose only external users are example
+	// packages. These are only reported when Options.ExamplesOnly is set.
+	ExamplesOnly bool `json:"examplesOnly,omitempty"`
+	// MockOnlyInterface is set for interfaces whose only references from
+	// other packages are in generated mock files. The interface may exist
+	// only to be mocked in tests. These are only reported when
+	// Options.MockOnly is set.
+	MockOnlyInterface bool `json:"mockOnlyInterface,omitempty"`
+	// WillBeOrphaned is set for exports whose only external users are
+	// packages matching Options.PendingDeletion. They are used today, but
+	// will be over-exported once those packages are deleted.
+	WillBeOrphaned bool `json:"willBeOrphaned,omitempty"`
+	// TestCoverageGap is set for exports in non-internal packages whose only
+	// external users are tests. These are only reported when
+	// Options.StrictTest is set.
+	TestCoverageGap bool `json:"testCoverageGap,omitempty"`
+	// InternalStrict is set for exports in internal packages whose only
+	// external users are tests. These are only reported when
+	// Options.InternalStrict is set.
+	InternalStrict bool `json:"internalStrict,omitempty"`
+	// MaybeTemplateAccessed is set for methods of types whose values are
+	// passed to a text/template or html/template execution. Templates call
+	// methods by name at run time, which the analysis can't see.
+	MaybeTemplateAccessed bool `json:"maybeTemplateAccessed,omitempty"`
+	// PossibleDuplicates lists the other reported functions in the same
+	// package with an identical signature. It is only set when
+	// Options.SuggestDedup is set.
+	PossibleDuplicates []string `json:"possibleDuplicates,omitempty"`
+	// DeadParamTypes lists, in "pkgpath.Name" form, the reported types that
+	// a reported function or method takes as parameters, directly or
+	// through pointers, slices, arrays, maps or channels. The function and
+	// those types are dead API together, and removing the function first
+	// frees the types. It is only set when Options.UnusedParams is set.
+	DeadParamTypes []string `json:"deadParamTypes,omitempty"`
+	// MaybeUsedByIgnoredFile is set for exports referenced from a file with a
+	// "//go:build ignore" constraint, such as a code generator. Those files
+	// aren't part of the build, but would break if the export went away.
+	MaybeUsedByIgnoredFile bool `json:"maybeUsedByIgnoredFile,omitempty"`
+	// UsedByGenerated is set for exports referenced from generated files in
+	// packages that don't otherwise use them. Those references don't count
+	// with Options.IgnoreGeneratedCallers, but regenerating the code may
+	// still need the export. It is only set when IgnoreGeneratedCallers is
+	// set.
+	UsedByGenerated bool `json:"usedByGenerated,omitempty"`
+	// SharesTopLevelName is set for methods whose name is also the name of a
+	// package-level identifier in the same package, such as T.Get and Get.
+	// The name-clashes report in the command's help says why that matters.
+	// It is only set when Options.NameClashes is set.
+	SharesTopLevelName bool `json:"sharesTopLevelName,omitempty"`
+	// NoExternalImplementers is set for interfaces that no package-level type
+	// in another package implements. Such an interface isn't serving outside
+	// implementers either, so it is an especially safe candidate. It is only
+	// set when Options.NoExternalImplementers is set.
+	NoExternalImplementers bool `json:"noExternalImplementers,omitempty"`
+	// EnumGroup is set for constants whose type is a named type declared in
+	// the same package with at least one other constant, such as the values
+	// of an iota enum. It holds the type's name. It is only set when
+	// Options.EnumGroups is set.
+	EnumGroup string `json:"enumGroup,omitempty"`
+	// NameHint is set when the export's name, or a method's own name, matches
+	// Options.NameHintPattern. Names like InternalX or DebugX suggest the
+	// export was never meant to be public.
+	NameHint bool `json:"nameHint,omitempty"`
+	// SuggestedName is an unexported name for the identifier, or for a
+	// method's own name, that keeps initialisms in one case, such as
+	// httpClient for HTTPClient. It is only set when
+	// Options.RenameSuggestions is set, and is empty when the name would
+	// be a Go keyword or collide with a field or method of a method's
+	// receiver, or, for other exports, with a predeclared identifier or a
+	// name declared in the package.
+	SuggestedName string `json:"suggestedName,omitempty"`
+	// Reasons lists what each usage source found for the export, such as
+	// "no external call". It is only set when Options.DebugReasons is set.
+	Reasons []string `json:"reasons,omitempty"`
+	// OnlyUsedByUnreachable lists the unreachable functions, in
+	// "pkgpath.Name" form, whose references were all that kept the export
+	// from being reported. Deleting them frees the export. It is only set
+	// when Options.Transitive is set.
+	OnlyUsedByUnreachable []string `json:"onlyUsedByUnreachable,omitempty"`
+}
+
+// SetKeys sets Key on every export in r.
+func (r *Result) SetKeys() {
+	for i, exp := range r.Exports {
+		r.Exports[i].Key = exp.PkgPath + "." + exp.Name
+	}
+}
+
+// Instantiation lists the type arguments an exported generic function or
+// type is instantiated with across the program.
+type Instantiation struct {
+	Name    string `json:"name"`
+	PkgPath string `json:"package"`
+	// TypeArgs holds one entry per distinct instantiation, with the type
+	// arguments separated by ", ".
+	TypeArgs []string `json:"typeArgs"`
+}
+
+// BuildConfig describes the build configuration used to load packages.
+type BuildConfig struct {
+	GOOS   string   `json:"goos"`
+	GOARCH string   `json:"goarch"`
+	Tags   []string `json:"tags,omitempty"`
 }
 
 // Result contains the analysis results.
 type Result struct {
 	Exports []Export `json:"exports"`
+	// GeneratedAt is when the analysis ran, as reported by Options.Now.
+	GeneratedAt time.Time `json:"generatedAt"`
+	// SkippedPackages lists the IDs of packages left out of the analysis
+	// because they, or packages they import, contain errors. See
+	// Options.MaxLoadErrors.
+	SkippedPackages []string `json:"skippedPackages,omitempty"`
+	// AnalyzedPackages lists the paths of every package loaded for the
+	// analysis, sorted.
+	AnalyzedPackages []string `json:"analyzedPackages,omitempty"`
+	// TargetPackages lists the paths of the packages whose exports were
+	// considered, sorted.
+	TargetPackages []string `json:"targetPackages,omitempty"`
+	// PackageModules maps the paths of the target packages to the paths of
+	// their modules. Packages outside any module, as in GOPATH mode, are
+	// left out.
+	PackageModules map[string]string `json:"packageModules,omitempty"`
+	// BuildConfig is the build configuration packages were loaded with.
+	// Files excluded by it are not analyzed. It is only populated when
+	// Options.BuildConfig is set.
+	BuildConfig *BuildConfig `json:"buildConfig,omitempty"`
+	// Instantiations is only populated when Options.Instantiations is set.
+	Instantiations []Instantiation `json:"instantiations,omitempty"`
+	// WriteOnlyVars is only populated when Options.WriteOnlyVars is set.
+	WriteOnlyVars []WriteOnlyVar `json:"writeOnlyVars,omitempty"`
+	// OrphanPackages is only populated when Options.OrphanPackages is set.
+	OrphanPackages []string `json:"orphanPackages,omitempty"`
+	// Inventory is only populated when Options.Inventory is set.
+	Inventory []InventoryEntry `json:"inventory,omitempty"`
+	// UsedOnlyBy is only populated when Options.UsedOnlyBy is set. Its
+	// entries all have the "used" status.
+	UsedOnlyBy []InventoryEntry `json:"usedOnlyBy,omitempty"`
+	// InterfaceMap is only populated when Options.InterfaceMap is set. It
+	// maps exported types of the target packages to the interfaces they
+	// satisfy, both in "pkgpath.Name" form.
+	InterfaceMap map[string][]string `json:"interfaceMap,omitempty"`
+	// CalledMethods is only populated when Options.CalledMethods is set.
+	CalledMethods []CalledMethods `json:"calledMethods,omitempty"`
+}
+
+// InventoryEntry is an export of a target package with its disposition.
+type InventoryEntry struct {
+	Export
+	// Status is one of:
+	//   - "used": used outside its package, or by a template.
+	//   - "over-exported": reported in Result.Exports.
+	//   - "kept-generated": unused but declared in a generated file, which
+	//     isn't reported without Options.Generated.
+	//   - "suppressed": listed in Options.Keep.
+	Status string `json:"status"`
+	// UsedBy lists the known packages that use the export, sorted. It may be
+	// empty for a used export when the user is unknown, as with
+	// Options.ExternalUsage.
+	UsedBy []string `json:"usedBy,omitempty"`
 }
 
 // Options configures the analysis.
 type Options struct {
 	// Test includes test packages and executables in the analysis.
 	Test bool
+	// Tags are build tags to set when loading packages. Only files selected
+	// by these tags are analyzed, so usage from files for other tag sets
+	// isn't seen.
+	Tags []string
+	// AllTags adds to Tags every custom build tag that a //go:build line in
+	// the packages matching the patterns requires. This brings exports
+	// behind feature tags into the analysis. It is meant for discovery:
+	// mutually exclusive tags are all set at once, which can select
+	// conflicting files and make loading fail.
+	AllTags bool
+	// BuildFlags are passed verbatim to the go command when loading
+	// packages, after the -tags flag for Tags. This is an escape hatch for
+	// unusual build configurations. Malformed flags surface as load errors.
+	BuildFlags []string
+	// ExternalTestAsExternal counts usage from external test packages
+	// (foo_test) as external. They can only reach exported identifiers, so
+	// their usage shows the export is needed. When it is false they are
+	// treated as part of the package they test, like internal test files.
+	// The command sets it by default. It only matters when Test is set;
+	// without Test, test packages are not loaded at all.
+	ExternalTestAsExternal bool
+	// StrictTest also reports public API (exports in non-internal packages)
+	// whose only external users are tests, with TestCoverageGap set.
+	// StrictTest implies Test.
+	StrictTest bool
+	// InternalStrict also reports exports in internal packages whose only
+	// external users are tests, with InternalStrict set. Internal packages
+	// are never a public contract, so test-only usage doesn't justify their
+	// exports. InternalStrict implies Test.
+	InternalStrict bool
 	// Generated includes exports in generated Go files.
 	Generated bool
+	// IsGenerated reports whether a file is generated. Exports in files it
+	// returns true for are suppressed unless Generated is set, and
+	// IgnoreGeneratedCallers uses it to decide which callers to ignore.
+	// When nil, only the standard "Code generated ... DO NOT EDIT." comment
+	// is recognized.
+	IsGenerated func(filename string, file *ast.File) bool
+	// IgnoreGeneratedCallers doesn't count references from generated files
+	// as usage, so an export used only by generated code is reported. Calls
+	// through function values and interfaces are attributed to the file
+	// making the call, not the file that took the reference.
+	IgnoreGeneratedCallers bool
 	// Filter is a regular expression to filter which packages to report.
 	// The special value "<module>" reports only packages matching the
 	// modules of all analyzed packages.
@@ -47,92 +281,792 @@ type Options struct {
 	// Exclude is a list of package patterns to exclude from the results.
 	// Patterns use the same syntax as 'go list' (e.g., "./...", "github.com/foo/...").
 	Exclude []string
+	// ExamplesOnly also reports exports whose only external users are in
+	// packages with an "example" or "examples" path element. Usage from
+	// examples keeps an export alive, but may not reflect real consumers.
+	ExamplesOnly bool
+	// MockOnly also reports interfaces whose only references from other
+	// packages are in files generated by a mock generator such as mockgen,
+	// moq or mockery. Mock references still count as usage for everything
+	// else.
+	MockOnly bool
+	// Instantiations populates Result.Instantiations with the type arguments
+	// of every exported generic in the target packages. This helps decide
+	// whether a generic is more general than it needs to be.
+	Instantiations bool
+	// WriteOnlyVars populates Result.WriteOnlyVars with exported variables
+	// that other packages assign to but never read.
+	WriteOnlyVars bool
+	// OrphanPackages populates Result.OrphanPackages with the target
+	// packages, other than main packages, that no other package imports.
+	// All of their exports are over-exported, and the package may be dead.
+	OrphanPackages bool
+	// BuildConfig populates Result.BuildConfig. It runs the go command once
+	// more, so it is off unless the configuration is reported.
+	BuildConfig bool
+	// Inventory populates Result.Inventory with every export of the target
+	// packages that passes Filter and Exclude, whether it is reported or
+	// not, along with its status and users. This is a census of the full
+	// exported API.
+	Inventory bool
+	// UsedOnlyBy is a list of package patterns. Exports of the target
+	// packages that are used outside their package, but only by packages
+	// matching these patterns, are listed in Result.UsedOnlyBy. They are the
+	// exports that would become unused if those packages were extracted or
+	// deleted. Exports with an unknown user, as with ExternalUsage, are never
+	// listed.
+	UsedOnlyBy []string
+	// PendingDeletion is a list of patterns for packages that are scheduled
+	// to be deleted. Usage from these packages doesn't count, so exports
+	// that only they use are reported with WillBeOrphaned set. Exports of
+	// the packages themselves are not reported. Exports with an unknown
+	// user, as with ExternalUsage, are still counted as used.
+	PendingDeletion []string
+	// InterfaceMap populates Result.InterfaceMap with the named interfaces,
+	// from any loaded package or dependency, that each exported type of the
+	// target packages satisfies. It shows why methods that aren't called
+	// directly are kept.
+	InterfaceMap bool
+	// CalledMethods populates Result.CalledMethods with the methods other
+	// packages use of each exported type that a function in another package
+	// takes as a parameter. A parameter whose callers need only some of the
+	// methods could be a smaller interface.
+	CalledMethods bool
+	// UnusedParams sets Export.DeadParamTypes on reported functions and
+	// methods that take reported types as parameters.
+	UnusedParams bool
+	// NoExternalImplementers sets Export.NoExternalImplementers on reported
+	// interfaces that no type in another package implements. It checks each
+	// of those interfaces against every named type in the analyzed
+	// packages.
+	NoExternalImplementers bool
+	// EnumGroups sets Export.EnumGroup on reported constants that share a
+	// named type with other constants in their package.
+	EnumGroups bool
+	// NameClashes sets Export.SharesTopLevelName on reported methods named
+	// like a package-level identifier in the same package.
+	NameClashes bool
+	// EntrypointPattern is a regular expression matched against the names of
+	// exported functions in the target packages. Matching functions are used
+	// as additional roots for the analysis and are never reported. This
+	// supports frameworks that wire up functions by naming convention. An
+	// overly broad pattern will hide genuinely dead code.
+	EntrypointPattern string
+	// NameHintPattern is a regular expression matched against the names of
+	// reported exports, using only the method name for methods. Matches have
+	// Export.NameHint set. It has no default.
+	NameHintPattern string
+	// RenameSuggestions sets Export.SuggestedName on reported exports. It is
+	// experimental and the suggested names may change.
+	RenameSuggestions bool
+	// CollapseMethods omits methods from the result when their receiver type
+	// is also reported. Unexporting the type takes care of them.
+	CollapseMethods bool
+	// UndocumentedOnly restricts the result to exports whose declaration has
+	// no doc comment. These are the least likely to be intended as public API.
+	UndocumentedOnly bool
+	// IgnoreDeprecated leaves out exports whose doc comment has a paragraph
+	// starting with "Deprecated: ". They are already on their way out.
+	IgnoreDeprecated bool
+	// Transitive ignores references from functions and methods that are
+	// unreachable from the entry points, so an export used only by dead code,
+	// such as another over-exported function nothing calls, is reported too.
+	// Such exports have OnlyUsedByUnreachable set.
+	Transitive bool
+	// DebugReasons sets Export.Reasons on reported exports to show what each
+	// usage source found. This is useful to check a finding isn't caused by a
+	// missed detection path.
+	DebugReasons bool
+	// SuggestDedup groups reported functions in the same package by identical
+	// signature and sets Export.PossibleDuplicates on groups of more than one.
+	SuggestDedup bool
+	// Keep is a list of identifiers to exclude from the results. Entries are
+	// exact matches in the form "pkgpath.Name" or "pkgpath.Type.Method".
+	Keep []string
+	// Only restricts the analysis to these identifiers, in the same form as
+	// Keep. Usage is still gathered from the whole program, but only these
+	// are considered for the result. Run returns an error if one of them
+	// isn't an export of a target package.
+	Only []string
+	// ExternalUsage lists identifiers, in the same form as Keep, that are
+	// known to be used by consumers outside the analyzed program, such as
+	// downstream modules of a public library. They are treated as used by an
+	// unknown package.
+	ExternalUsage []string
+	// DirectiveScanners are run, after the built-in scanner for cgo
+	// "//export" directives, over every analyzed file to find exports
+	// referenced by comment directives or other conventions the type
+	// checker doesn't see. The exports they name are treated as used by the
+	// scanned file's package, or by an unknown package when the marker is in
+	// the export's own package.
+	DirectiveScanners []DirectiveScanner
+	// UnexportedReceivers includes exported methods on unexported types.
+	// These are reported with UnreachableReceiver set.
+	UnexportedReceivers bool
+	// MaxLoadErrors is the number of packages with errors to tolerate. Packages
+	// with errors, and packages that import them, are left out of the analysis
+	// and listed in Result.SkippedPackages. Zero tolerates none and a negative
+	// value tolerates any number.
+	MaxLoadErrors int
+	// RequireSSA makes Run fail if any target package wasn't built into the
+	// SSA program, including target packages skipped under MaxLoadErrors.
+	// The exports of such packages are never seen, so without it they are
+	// silently missing from an otherwise clean result.
+	RequireSSA bool
 	// Dir is the directory to use for the analysis. If empty, the current
 	// working directory is used.
 	Dir string
+	// TargetMatcher, if set, selects the target packages instead of the
+	// patterns passed to Run. It is called for every loaded package, including
+	// test variants, and a package is a target if any of its variants match.
+	// It replaces pattern-based target selection rather than adding to it.
+	TargetMatcher func(*packages.Package) bool
+	// PathBase, if set, is the directory that the file names of positions in
+	// the result are made relative to, such as the module root. This makes
+	// positions independent of where the program runs. By default they are
+	// absolute.
+	PathBase string
+	// Now returns the time recorded in Result.GeneratedAt. If nil, time.Now
+	// is used. Set it for reproducible output.
+	Now func() time.Time
 }
 
 func Run(patterns []string, opts *Options) (*Result, error) {
-	if opts == nil {
-		opts = &Options{}
+	opts, err := prepareOptions(opts, patterns)
+	if err != nil {
+		return nil, err
+	}
+	nameHint, err := compilePattern(opts.NameHintPattern, "name hint")
+	if err != nil {
+		return nil, err
 	}
+	entrypointPattern, err := compilePattern(opts.EntrypointPattern, "entrypoint")
+	if err != nil {
+		return nil, err
+	}
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	generatedAt := now()
 
-	allPkgs, needsTargetMatching, err := loadPackages(*opts, patterns)
+	l, err := load(*opts, patterns)
 	if err != nil {
 		return nil, err
 	}
+	result := &Result{}
+	if len(l.exports) > 0 {
+		a, err := l.analyze(entrypointPattern)
+		if err != nil {
+			return nil, err
+		}
+		result = a.buildResult()
+		a.annotate(result, nameHint)
+		a.addReports(result)
+	}
+	result.GeneratedAt = generatedAt
+	result.SkippedPackages = l.skipped
+	result.AnalyzedPackages = analyzedPackages(l.allPkgs)
+	result.TargetPackages = slices.Sorted(maps.Keys(l.targetPaths))
+	result.PackageModules = packageModules(l.allPkgs, l.targetPaths)
+	result.BuildConfig = l.buildConfig
+	result.Instantiations = l.instantiations
+	result.OrphanPackages = l.orphans
+	if opts.PathBase != "" {
+		relativizePositions(result, opts.PathBase)
+	}
+	return result, nil
+}
+
+// prepareOptions returns a copy of opts, or of the zero Options if it is nil,
+// with the settings other options imply filled in: Test for StrictTest and
+// InternalStrict, Dir with symlinks resolved, and Tags with AllTags.
+func prepareOptions(opts *Options, patterns []string) (*Options, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.StrictTest || o.InternalStrict {
+		o.Test = true
+	}
+	// Load from the directory with symlinks resolved so that positions use
+	// the same paths as filepath.EvalSymlinks of the working directory.
+	if dir, ok := resolveDir(o.Dir); ok {
+		o.Dir = dir
+	}
+	if o.AllTags {
+		tags, err := discoverBuildTags(o, patterns)
+		if err != nil {
+			return nil, err
+		}
+		o.Tags = append(slices.Clone(o.Tags), tags...)
+	}
+	return &o, nil
+}
+
+// compilePattern compiles the regular expression of the option described by
+// name, or returns nil if it is empty. Options are checked before anything is
+// loaded so that a bad pattern fails fast.
+func compilePattern(pattern, name string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s pattern: %w", name, err)
+	}
+	return re, nil
+}
 
-	targetPaths := buildTargetPaths(allPkgs, patterns, needsTargetMatching)
+// loadedProgram is the program under analysis, built into SSA, with the
+// exports of its target packages and the reports that only need the loaded
+// packages.
+type loadedProgram struct {
+	opts           Options
+	allPkgs        []*packages.Package
+	targetPaths    map[string]bool
+	skipped        []string
+	filter         *regexp.Regexp
+	prog           *ssa.Program
+	ssaPkgs        []*ssa.Package
+	exports        map[string]Export
+	generated      map[string]bool
+	buildConfig    *BuildConfig
+	instantiations []Instantiation
+	orphans        []string
+}
 
-	filter, err := buildFilterPattern(*opts, allPkgs)
+// load loads and builds the program and collects the exports of the target
+// packages.
+func load(opts Options, patterns []string) (*loadedProgram, error) {
+	allPkgs, targetPaths, skipped, err := loadPackages(opts, patterns)
+	if err != nil {
+		return nil, err
+	}
+	l := &loadedProgram{opts: opts, allPkgs: allPkgs, targetPaths: targetPaths, skipped: skipped}
+	l.filter, err = buildFilterPattern(opts, allPkgs)
 	if err != nil {
 		return nil, err
 	}
+	if opts.Instantiations {
+		l.instantiations = findInstantiations(allPkgs, targetPaths)
+	}
+	if opts.OrphanPackages {
+		l.orphans = findOrphanPackages(opts, allPkgs, targetPaths)
+	}
 
-	// Build SSA program.
-	prog, pkgs := ssautil.Packages(allPkgs, ssa.InstantiateGenerics)
-	prog.Build()
+	l.prog, l.ssaPkgs = ssautil.Packages(allPkgs, ssa.InstantiateGenerics)
+	l.prog.Build()
+	if opts.RequireSSA {
+		err = checkSSAPackages(l.prog, allPkgs, targetPaths)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if opts.BuildConfig {
+		l.buildConfig = loadBuildConfig(opts)
+	}
 
-	exports, generated := collectExportsSSA(*opts, prog, allPkgs, targetPaths)
-	if len(exports) == 0 {
-		return &Result{}, nil
+	l.exports, l.generated = collectExportsSSA(opts, l.prog, allPkgs, targetPaths)
+	if len(opts.Only) > 0 {
+		l.exports, err = onlyExports(l.exports, opts.Only)
+		if err != nil {
+			return nil, err
+		}
 	}
+	return l, nil
+}
 
-	roots, err := findEntryPoints(pkgs)
+// analyzedProgram is a loadedProgram with the usage of its exports.
+type analyzedProgram struct {
+	*loadedProgram
+	res             *rta.Result
+	ignoredFiles    map[string]bool
+	unreachable     *unreachableFuncs
+	externallyUsed  usage
+	nonTestUsed     usage
+	generatedUsed   usage
+	mockOnly        map[string]bool
+	templates       *templateAccess
+	ignoredFileRefs map[string]bool
+}
+
+// analyze runs RTA from the main functions and the functions matching
+// entrypointPattern, then finds the usage of the exports once for every
+// question the options ask.
+func (l *loadedProgram) analyze(entrypointPattern *regexp.Regexp) (*analyzedProgram, error) {
+	opts := l.opts
+	entrypoints := findConventionEntryPoints(entrypointPattern, l.prog, l.allPkgs, l.targetPaths)
+	roots, err := findEntryPoints(l.ssaPkgs, entrypoints)
 	if err != nil {
 		return nil, err
 	}
-
 	res := rta.Analyze(roots, true)
 	if res == nil {
 		return nil, fmt.Errorf("RTA analysis failed")
 	}
 
-	externallyUsed := findExternalUsage(*opts, res, allPkgs, targetPaths)
-	markRuntimeTypes(res, targetPaths, externallyUsed)
+	a := &analyzedProgram{loadedProgram: l, res: res}
+	toolsFiles := findToolsFiles(l.allPkgs)
+	a.ignoredFiles = maps.Clone(toolsFiles)
+	if opts.IgnoreGeneratedCallers {
+		maps.Copy(a.ignoredFiles, findGeneratedFiles(l.allPkgs, opts.IsGenerated))
+	}
+	// With Transitive, references from unreachable functions don't count.
+	if opts.Transitive {
+		a.unreachable = findUnreachableFuncs(l.prog, res, l.allPkgs)
+	}
+	a.externallyUsed = a.findUsage(a.ignoredFiles)
+	for _, fn := range entrypoints {
+		a.externallyUsed.add(usageKey{pkgPath: fn.Pkg.Pkg.Path(), name: fn.Name()}, "")
+	}
+
+	// For IgnoreGeneratedCallers, find usage again with generated files to
+	// tell which exports generated code uses.
+	if opts.IgnoreGeneratedCallers {
+		a.generatedUsed = a.findUsage(toolsFiles)
+	}
+
+	// For StrictTest and InternalStrict, find usage again without tests to
+	// tell which exports are only used by tests.
+	if opts.StrictTest || opts.InternalStrict {
+		nonTestFiles := findTestFiles(l.allPkgs)
+		maps.Copy(nonTestFiles, a.ignoredFiles)
+		a.nonTestUsed = a.findUsage(nonTestFiles)
+	}
+
+	// For MockOnly, find usage again without mock files to tell which
+	// interfaces are only referenced by their mocks.
+	if opts.MockOnly {
+		nonMockFiles := findMockFiles(l.allPkgs, opts.IsGenerated)
+		maps.Copy(nonMockFiles, a.ignoredFiles)
+		a.mockOnly = findMockOnlyInterfaces(l.allPkgs, l.exports, a.externallyUsed, a.findUsage(nonMockFiles))
+	}
+
+	a.templates = findTemplateAccess(res, l.targetPaths)
+	a.ignoredFileRefs = findIgnoredFileRefs(l.allPkgs, l.targetPaths)
+	return a, nil
+}
 
-	return buildResult(*opts, exports, externallyUsed, generated, filter), nil
+// findUsage finds the usage of the exports from everything but ignoredFiles,
+// counting runtime types, Options.ExternalUsage and directives.
+func (a *analyzedProgram) findUsage(ignoredFiles map[string]bool) usage {
+	used := findExternalUsage(a.opts, a.res, a.allPkgs, a.targetPaths, ignoredFiles, a.unreachable)
+	markRuntimeTypes(a.res, a.targetPaths, used)
+	addExternalUsage(a.opts.ExternalUsage, a.exports, used)
+	addDirectiveUsage(a.opts, a.allPkgs, a.exports, ignoredFiles, used)
+	return used
 }
 
-func loadPackages(opts Options, patterns []string) ([]*packages.Package, bool, error) {
-	loadPatterns := patterns
-	needsTargetMatching := false
-	for _, p := range patterns {
-		if p != "./..." && p != "..." {
-			loadPatterns = []string{"./..."}
-			needsTargetMatching = true
-			break
+// annotate filters the reported exports and sets the annotations that look
+// at them together, as the options ask.
+func (a *analyzedProgram) annotate(result *Result, nameHint *regexp.Regexp) {
+	opts := a.opts
+	if opts.UndocumentedOnly {
+		result.Exports = removeDocumented(result.Exports, a.allPkgs, a.targetPaths)
+	}
+	if opts.IgnoreDeprecated {
+		result.Exports = removeDeprecated(result.Exports, a.allPkgs, a.targetPaths)
+	}
+	if opts.SuggestDedup {
+		markPossibleDuplicates(result.Exports, a.allPkgs)
+	}
+	if opts.NameClashes {
+		markTopLevelNameClashes(result.Exports, a.allPkgs)
+	}
+	if opts.NoExternalImplementers {
+		markNoExternalImplementers(result.Exports, a.allPkgs)
+	}
+	if opts.EnumGroups {
+		markEnumGroups(result.Exports, a.allPkgs)
+	}
+	if opts.UnusedParams {
+		markDeadParamTypes(result.Exports, a.allPkgs)
+	}
+	markNameHints(result.Exports, nameHint)
+	if opts.RenameSuggestions {
+		markSuggestedNames(result.Exports, a.allPkgs)
+	}
+	if opts.DebugReasons {
+		addDebugReasons(opts, result.Exports, a.res, a.allPkgs, a.targetPaths, a.ignoredFiles, a.unreachable)
+	}
+}
+
+// addReports adds the reports that need the usage of the program to result,
+// as the options ask.
+func (a *analyzedProgram) addReports(result *Result) {
+	if a.opts.WriteOnlyVars {
+		result.WriteOnlyVars = findWriteOnlyVars(a.opts, a.res, a.targetPaths)
+	}
+	if a.opts.InterfaceMap {
+		result.InterfaceMap = findInterfaceMap(a.allPkgs, a.targetPaths)
+	}
+	if a.opts.CalledMethods {
+		result.CalledMethods = findCalledMethods(a.allPkgs, a.targetPaths, a.externallyUsed)
+	}
+}
+
+// relativizePositions makes the file names of positions in result relative
+// to base. Files that can't be made relative keep their absolute names.
+func relativizePositions(result *Result, base string) {
+	base, err := filepath.Abs(base)
+	if err != nil {
+		return
+	}
+	resolved, err := filepath.EvalSymlinks(base)
+	if err == nil {
+		base = resolved
+	}
+	rel := func(pos *Position) {
+		relPath, err := filepath.Rel(base, pos.File)
+		if err == nil {
+			pos.File = relPath
 		}
 	}
+	for i := range result.Exports {
+		rel(&result.Exports[i].Position)
+	}
+	for i := range result.Inventory {
+		rel(&result.Inventory[i].Position)
+	}
+	for i := range result.UsedOnlyBy {
+		rel(&result.UsedOnlyBy[i].Position)
+	}
+	for i := range result.WriteOnlyVars {
+		rel(&result.WriteOnlyVars[i].Position)
+	}
+}
 
-	cfg := &packages.Config{
-		Mode:  packages.LoadAllSyntax | packages.NeedModule,
-		Tests: opts.Test,
-		Dir:   opts.Dir,
+// findOrphanPackages returns the sorted paths of target packages, other than
+// main packages and external test packages, that no other package imports.
+// Imports from a package's own tests only count when external tests are
+// analyzed as separate packages, and imports from synthesized test mains
+// never count.
+func findOrphanPackages(opts Options, allPkgs []*packages.Package, targetPaths map[string]bool) []string {
+	imported := make(map[string]bool)
+	for _, pkg := range allPkgs {
+		if strings.HasSuffix(pkg.PkgPath, ".test") {
+			continue
+		}
+		importer := normalizePkgPath(pkg.PkgPath, opts)
+		for _, imp := range pkg.Imports {
+			if imp.PkgPath != importer {
+				imported[imp.PkgPath] = true
+			}
+		}
 	}
-	allPkgs, err := packages.Load(cfg, loadPatterns...)
+	var orphans []string
+	for _, pkg := range allPkgs {
+		if !targetPaths[pkg.PkgPath] || pkg.Name == "main" || strings.HasSuffix(pkg.Name, "_test") {
+			continue
+		}
+		if !imported[pkg.PkgPath] {
+			orphans = append(orphans, pkg.PkgPath)
+		}
+	}
+	slices.Sort(orphans)
+	return slices.Compact(orphans)
+}
+
+// analyzedPackages returns the sorted, distinct paths of pkgs. Test variants
+// share their package's path.
+func analyzedPackages(pkgs []*packages.Package) []string {
+	paths := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		paths[i] = pkg.PkgPath
+	}
+	slices.Sort(paths)
+	return slices.Compact(paths)
+}
+
+// packageModules maps the paths of the target packages in pkgs to the paths
+// of their modules.
+func packageModules(pkgs []*packages.Package, targetPaths map[string]bool) map[string]string {
+	modules := make(map[string]string)
+	for _, pkg := range pkgs {
+		if targetPaths[pkg.PkgPath] && pkg.Module != nil {
+			modules[pkg.PkgPath] = pkg.Module.Path
+		}
+	}
+	return modules
+}
+
+// findInstantiations collects the type arguments of every instantiation of an
+// exported generic declared in a target package, sorted by package and name.
+func findInstantiations(allPkgs []*packages.Package, targetPaths map[string]bool) []Instantiation {
+	typeArgs := make(map[types.Object]map[string]bool)
+	for _, pkg := range allPkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for ident, inst := range pkg.TypesInfo.Instances {
+			obj := pkg.TypesInfo.Uses[ident]
+			if !recordedInstance(obj, inst, targetPaths) {
+				continue
+			}
+			args := make([]string, 0, inst.TypeArgs.Len())
+			for t := range inst.TypeArgs.Types() {
+				args = append(args, types.TypeString(t, nil))
+			}
+			if typeArgs[obj] == nil {
+				typeArgs[obj] = make(map[string]bool)
+			}
+			typeArgs[obj][strings.Join(args, ", ")] = true
+		}
+	}
+
+	result := make([]Instantiation, 0, len(typeArgs))
+	for obj, args := range typeArgs {
+		result = append(result, Instantiation{
+			Name:     obj.Name(),
+			PkgPath:  obj.Pkg().Path(),
+			TypeArgs: slices.Sorted(maps.Keys(args)),
+		})
+	}
+	slices.SortFunc(result, func(a, b Instantiation) int {
+		return cmp.Or(cmp.Compare(a.PkgPath, b.PkgPath), cmp.Compare(a.Name, b.Name))
+	})
+	return result
+}
+
+// recordedInstance reports whether findInstantiations records inst, an
+// instantiation of obj: an exported generic function or type declared in a
+// target package, instantiated with concrete types.
+func recordedInstance(obj types.Object, inst types.Instance, targetPaths map[string]bool) bool {
+	if obj == nil || obj.Pkg() == nil || !obj.Exported() || !targetPaths[obj.Pkg().Path()] {
+		return false
+	}
+	// Skip methods. Their receiver type arguments are recorded with the
+	// type.
+	if fn, ok := obj.(*types.Func); ok && fn.Signature().Recv() != nil {
+		return false
+	}
+	// Skip uses inside generic code, such as method receivers, that are
+	// instantiated with their own type parameters.
+	return !slices.ContainsFunc(slices.Collect(inst.TypeArgs.Types()), isTypeParam)
+}
+
+func isTypeParam(t types.Type) bool {
+	_, ok := types.Unalias(t).(*types.TypeParam)
+	return ok
+}
+
+// loadPackages loads the whole program and returns it along with the paths
+// of the target packages. Every main module (all workspace modules when a
+// go.work file is in use) is loaded in full so that usage from outside the
+// target packages is seen.
+func loadPackages(opts Options, patterns []string) (allPkgs []*packages.Package, targetPaths map[string]bool, skipped []string, _ error) {
+	if opts.TargetMatcher == nil {
+		var err error
+		targetPaths, err = loadTargetPaths(opts, patterns)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	allPkgs, skipped, err := loadProgram(opts)
 	if err != nil {
-		return nil, false, fmt.Errorf("load packages: %w", err)
+		return nil, nil, nil, err
 	}
-	if packages.PrintErrors(allPkgs) > 0 {
-		return nil, false, fmt.Errorf("packages contain errors")
+	if opts.TargetMatcher != nil {
+		targetPaths = matchTargetPaths(allPkgs, opts.TargetMatcher)
 	}
-	return allPkgs, needsTargetMatching, nil
+	return allPkgs, targetPaths, skipped, nil
 }
 
-func buildTargetPaths(allPkgs []*packages.Package, patterns []string, needsTargetMatching bool) map[string]bool {
+// matchTargetPaths returns the paths of the packages in allPkgs that match
+// selects as targets.
+func matchTargetPaths(allPkgs []*packages.Package, match func(*packages.Package) bool) map[string]bool {
 	targetPaths := make(map[string]bool)
 	for _, pkg := range allPkgs {
-		if !needsTargetMatching || matchPackagePatterns(patterns, pkg.PkgPath) {
+		if match(pkg) {
 			targetPaths[pkg.PkgPath] = true
 		}
 	}
 	return targetPaths
 }
 
-func findEntryPoints(pkgs []*ssa.Package) ([]*ssa.Function, error) {
+// buildFlags returns the go command flags for loading packages with opts.
+func buildFlags(opts Options) []string {
+	var flags []string
+	if len(opts.Tags) > 0 {
+		flags = append(flags, "-tags="+strings.Join(opts.Tags, ","))
+	}
+	return append(flags, opts.BuildFlags...)
+}
+
+// loadTargetPaths returns the paths of the packages matching patterns.
+func loadTargetPaths(opts Options, patterns []string) (map[string]bool, error) {
+	targetPkgs, err := packages.Load(&packages.Config{
+		Mode:       packages.NeedName,
+		Tests:      opts.Test,
+		Dir:        opts.Dir,
+		BuildFlags: buildFlags(opts),
+	}, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("load packages: %w", err)
+	}
+	errored := packages.PrintErrors(targetPkgs)
+	// Patterns that match nothing may still produce a package, without a
+	// name, to carry the error.
+	if !slices.ContainsFunc(targetPkgs, func(pkg *packages.Package) bool { return pkg.Name != "" }) {
+		return nil, fmt.Errorf("no packages matched patterns: %v", patterns)
+	}
+	if errored > 0 {
+		return nil, fmt.Errorf("packages contain errors")
+	}
+	targetPaths := make(map[string]bool)
+	for _, pkg := range targetPkgs {
+		targetPaths[pkg.PkgPath] = true
+	}
+	return targetPaths, nil
+}
+
+// loadProgram loads every package in the main modules. Broken packages are
+// dropped and returned in skipped, within the limit set by
+// opts.MaxLoadErrors.
+func loadProgram(opts Options) (allPkgs []*packages.Package, skipped []string, _ error) {
+	loadPatterns := []string{"./..."}
+	modules := mainModules(opts)
+	if len(modules) > 0 {
+		loadPatterns = make([]string, len(modules))
+		for i, mod := range modules {
+			loadPatterns[i] = mod + "/..."
+		}
+	}
+	cfg := &packages.Config{
+		Mode:       packages.LoadAllSyntax | packages.NeedModule,
+		Tests:      opts.Test,
+		Dir:        opts.Dir,
+		BuildFlags: buildFlags(opts),
+	}
+	allPkgs, err := packages.Load(cfg, loadPatterns...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load packages: %w", err)
+	}
+	if packages.PrintErrors(allPkgs) == 0 {
+		return allPkgs, nil, nil
+	}
+	allPkgs, skipped, errored := dropBrokenPackages(allPkgs)
+	if opts.MaxLoadErrors == 0 {
+		return nil, nil, fmt.Errorf("packages contain errors")
+	}
+	if opts.MaxLoadErrors > 0 && errored > opts.MaxLoadErrors {
+		return nil, nil, fmt.Errorf("%d packages contain errors, more than the maximum of %d", errored, opts.MaxLoadErrors)
+	}
+	return allPkgs, skipped, nil
+}
+
+// dropBrokenPackages removes packages with errors, and packages that import
+// them, from pkgs. It returns the remaining packages, the IDs of the removed
+// ones, and the number of packages with errors.
+func dropBrokenPackages(pkgs []*packages.Package) (kept []*packages.Package, skipped []string, errored int) {
+	broken := make(map[*packages.Package]bool)
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if len(pkg.Errors) > 0 {
+			errored++
+			broken[pkg] = true
+			return
+		}
+		for _, imp := range pkg.Imports {
+			if broken[imp] {
+				broken[pkg] = true
+				return
+			}
+		}
+	})
+	for _, pkg := range pkgs {
+		if broken[pkg] {
+			skipped = append(skipped, pkg.ID)
+			continue
+		}
+		kept = append(kept, pkg)
+	}
+	return kept, skipped, errored
+}
+
+// checkSSAPackages returns an error listing the target packages that have
+// no SSA package in prog.
+func checkSSAPackages(prog *ssa.Program, pkgs []*packages.Package, targetPaths map[string]bool) error {
+	built := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if prog.Package(pkg.Types) != nil {
+			built[pkg.PkgPath] = true
+		}
+	}
+	var missing []string
+	for pkgPath := range targetPaths {
+		if !built[pkgPath] {
+			missing = append(missing, pkgPath)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	slices.Sort(missing)
+	return fmt.Errorf("target packages not built into SSA: %s", strings.Join(missing, ", "))
+}
+
+// resolveDir returns dir, or the working directory if dir is empty, with
+// symlinks resolved. It returns false if dir can't be resolved.
+func resolveDir(dir string) (string, bool) {
+	if dir == "" {
+		var err error
+		dir, err = os.Getwd()
+		if err != nil {
+			return "", false
+		}
+	}
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", false
+	}
+	return resolved, true
+}
+
+// mainModules returns the paths of the main modules for opts.Dir: the
+// enclosing module, or every module in the workspace when a go.work file is
+// in use. It returns nil when modules aren't in use.
+func mainModules(opts Options) []string {
+	out, err := goCommand(opts, "list", "-m", "-f", "{{.Path}}").Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(out))
+}
+
+// loadBuildConfig returns the build configuration the go command uses to
+// load packages with opts. It returns nil if the go command fails.
+func loadBuildConfig(opts Options) *BuildConfig {
+	cmd := goCommand(opts, "list", "-e", "-f", `{{context.GOOS}} {{context.GOARCH}} {{join context.BuildTags ","}}`, "unsafe")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) < 2 || len(fields) > 3 {
+		return nil
+	}
+	config := &BuildConfig{GOOS: fields[0], GOARCH: fields[1]}
+	if len(fields) == 3 {
+		config.Tags = strings.Split(fields[2], ",")
+	}
+	return config
+}
+
+// goCommand returns a go command run in opts.Dir with the build flags that
+// packages are loaded with. Like packages.Load, it inherits the environment,
+// including GOFLAGS.
+func goCommand(opts Options, verb string, args ...string) *exec.Cmd {
+	cmd := exec.Command("go", slices.Concat([]string{verb}, buildFlags(opts), args)...)
+	cmd.Dir = opts.Dir
+	return cmd
+}
+
+// findEntryPoints returns the init and main functions of the main packages in
+// pkgs, followed by extra.
+func findEntryPoints(pkgs []*ssa.Package, extra []*ssa.Function) ([]*ssa.Function, error) {
 	mains := ssautil.MainPackages(pkgs)
-	if len(mains) == 0 {
+	if len(mains) == 0 && len(extra) == 0 {
 		return nil, fmt.Errorf("no main packages found")
 	}
 
@@ -147,10 +1081,54 @@ func findEntryPoints(pkgs []*ssa.Package) ([]*ssa.Function, error) {
 			roots = append(roots, main)
 		}
 	}
-	return roots, nil
+	return append(roots, extra...), nil
+}
+
+// findConventionEntryPoints returns the exported functions in target packages
+// whose names match pattern, along with the init functions of their packages.
+// A nil pattern matches nothing.
+func findConventionEntryPoints(
+	pattern *regexp.Regexp,
+	prog *ssa.Program,
+	allPkgs []*packages.Package,
+	targetPaths map[string]bool,
+) []*ssa.Function {
+	if pattern == nil {
+		return nil
+	}
+	var roots []*ssa.Function
+	for _, pkg := range allPkgs {
+		if !targetPaths[pkg.PkgPath] {
+			continue
+		}
+		ssaPkg := prog.Package(pkg.Types)
+		if ssaPkg == nil {
+			continue
+		}
+		matched := matchingFuncs(pattern, ssaPkg)
+		roots = append(roots, matched...)
+		init := ssaPkg.Func("init")
+		if len(matched) > 0 && init != nil {
+			roots = append(roots, init)
+		}
+	}
+	return roots
+}
+
+// matchingFuncs returns the exported package-level functions of ssaPkg whose
+// names match pattern.
+func matchingFuncs(pattern *regexp.Regexp, ssaPkg *ssa.Package) []*ssa.Function {
+	var matched []*ssa.Function
+	for _, mem := range ssaPkg.Members {
+		fn, ok := mem.(*ssa.Function)
+		if ok && token.IsExported(fn.Name()) && pattern.MatchString(fn.Name()) {
+			matched = append(matched, fn)
+		}
+	}
+	return matched
 }
 
-func markRuntimeTypes(res *rta.Result, targetPaths, externallyUsed map[string]bool) {
+func markRuntimeTypes(res *rta.Result, targetPaths map[string]bool, externallyUsed usage) {
 	res.RuntimeTypes.Iterate(func(t types.Type, _ any) {
 		named, ok := t.(*types.Named)
 		if !ok || named.Obj() == nil || named.Obj().Pkg() == nil {
@@ -158,7 +1136,7 @@ func markRuntimeTypes(res *rta.Result, targetPaths, externallyUsed map[string]bo
 		}
 		pkgPath := named.Obj().Pkg().Path()
 		if targetPaths[pkgPath] {
-			externallyUsed[pkgPath+"."+named.Obj().Name()] = true
+			externallyUsed.add(usageKey{pkgPath: pkgPath, name: named.Obj().Name()}, "")
 		}
 	})
 }
@@ -177,10 +1155,22 @@ func collectExportsSSA(
 			continue
 		}
 
-		// Track generated files
+		// Track generated files. Stringer output is always skipped: its
+		// String methods are called through fmt.Stringer in ways that are
+		// easy to miss, so they are never reported, whatever opts.IsGenerated
+		// says. Other generated files are skipped unless opts.Generated is
+		// set. The inventory needs their exports too; buildResult leaves them
+		// out of the report.
+		skipGenerated := !opts.Generated && !opts.Inventory
+		skipped := make(map[string]bool)
 		for _, file := range pkg.Syntax {
-			if ast.IsGenerated(file) {
-				generated[pkg.Fset.File(file.Pos()).Name()] = true
+			filename := pkg.Fset.File(file.Pos()).Name()
+			isGenerated := isGeneratedFile(opts.IsGenerated, filename, file)
+			if isGenerated {
+				generated[filename] = true
+			}
+			if isStringerFile(file) || (isGenerated && skipGenerated) {
+				skipped[filename] = true
 			}
 		}
 
@@ -189,28 +1179,41 @@ func collectExportsSSA(
 			continue
 		}
 
-		// Pass nil for generated map when includeGenerated is true to skip filtering
-		genMap := generated
-		if opts.Generated {
-			genMap = nil
-		}
 		c := &exportCollector{
-			prog:      prog,
-			exports:   exports,
-			generated: genMap,
-			pkgPath:   pkg.PkgPath,
+			prog:                prog,
+			exports:             exports,
+			generated:           skipped,
+			pkgPath:             pkg.PkgPath,
+			unexportedReceivers: opts.UnexportedReceivers,
 		}
 		c.collectPackageExports(ssaPkg)
 	}
 	return exports, generated
 }
 
+// isStringerFile reports whether file was generated by stringer or enumer.
+func isStringerFile(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, c := range group.List {
+			if strings.HasPrefix(c.Text, `// Code generated by "stringer `) ||
+				strings.HasPrefix(c.Text, `// Code generated by "enumer `) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // exportCollector holds shared state for collecting exports from a package.
 type exportCollector struct {
-	prog      *ssa.Program
-	exports   map[string]Export
-	generated map[string]bool
-	pkgPath   string
+	prog                *ssa.Program
+	exports             map[string]Export
+	generated           map[string]bool
+	pkgPath             string
+	unexportedReceivers bool
 }
 
 // addExport adds an export to the exports map if the position is not in a generated file.
@@ -253,24 +1256,34 @@ func (c *exportCollector) collectFunctionExport(fn *ssa.Function) {
 }
 
 func (c *exportCollector) collectTypeExport(m *ssa.Type) {
-	if !token.IsExported(m.Name()) {
+	exported := token.IsExported(m.Name())
+	if !exported && !c.unexportedReceivers {
 		return
 	}
-	if !c.addExport(m.Name(), "type", m.Pos()) {
+	if exported && !c.addExport(m.Name(), "type", m.Pos()) {
 		return
 	}
 
 	// Collect methods on this type (both value and pointer receivers)
-	// Type aliases don't have their own methods, so skip method collection for them
+	// Type aliases don't have their own methods, so skip method collection
+	// for them. Methods declared with an alias receiver are collected with
+	// the aliased type.
 	named, ok := m.Object().Type().(*types.Named)
 	if !ok {
 		return
 	}
-	c.collectMethodsFromMethodSet(m.Name(), c.prog.MethodSets.MethodSet(named))
-	c.collectMethodsFromMethodSet(m.Name(), c.prog.MethodSets.MethodSet(types.NewPointer(named)))
+	// The SSA program has no method values for generic types, only for their
+	// instantiations, so use the declared methods instead. Promoted methods
+	// are collected with the embedded type that declares them.
+	if named.TypeParams().Len() > 0 {
+		c.collectDeclaredMethods(m.Name(), !exported, named)
+		return
+	}
+	c.collectMethodsFromMethodSet(m.Name(), !exported, c.prog.MethodSets.MethodSet(named))
+	c.collectMethodsFromMethodSet(m.Name(), !exported, c.prog.MethodSets.MethodSet(types.NewPointer(named)))
 }
 
-func (c *exportCollector) collectMethodsFromMethodSet(typeName string, mset *types.MethodSet) {
+func (c *exportCollector) collectMethodsFromMethodSet(typeName string, unreachable bool, mset *types.MethodSet) {
 	for sel := range mset.Methods() {
 		if !sel.Obj().Exported() {
 			continue
@@ -285,7 +1298,28 @@ func (c *exportCollector) collectMethodsFromMethodSet(typeName string, mset *typ
 		if exists {
 			continue
 		}
-		c.addExport(methodName, "method", fn.Pos())
+		if c.addExport(methodName, "method", fn.Pos()) && unreachable {
+			exp := c.exports[methodKey]
+			exp.UnreachableReceiver = true
+			c.exports[methodKey] = exp
+		}
+	}
+}
+
+// collectDeclaredMethods adds the exported methods declared on the generic
+// type named. unreachable marks them as having an unexported receiver.
+func (c *exportCollector) collectDeclaredMethods(typeName string, unreachable bool, named *types.Named) {
+	for method := range named.Methods() {
+		if !method.Exported() {
+			continue
+		}
+		methodName := typeName + "." + method.Name()
+		if c.addExport(methodName, "method", method.Pos()) && unreachable {
+			methodKey := c.pkgPath + "." + methodName
+			exp := c.exports[methodKey]
+			exp.UnreachableReceiver = true
+			c.exports[methodKey] = exp
+		}
 	}
 }
 
@@ -303,48 +1337,140 @@ func (c *exportCollector) collectConstExport(cn *ssa.NamedConst) {
 	c.addExport(cn.Name(), "const", cn.Pos())
 }
 
+// usageKey identifies an export in a usage map. typeName is set for methods.
+// Building these from existing strings avoids allocating a string key for
+// every reference.
+type usageKey struct {
+	pkgPath, typeName, name string
+}
+
+// exportUsageKey returns the usageKey for exp.
+func exportUsageKey(exp Export) usageKey {
+	if typeName, methodName, ok := strings.Cut(exp.Name, "."); ok && exp.Kind == "method" {
+		return usageKey{pkgPath: exp.PkgPath, typeName: typeName, name: methodName}
+	}
+	return usageKey{pkgPath: exp.PkgPath, name: exp.Name}
+}
+
+// usage maps exports to the set of packages using them from outside the
+// export's package. An empty package path means the user is unknown.
+type usage map[usageKey]map[string]bool
+
+func (u usage) add(key usageKey, userPkg string) {
+	if u[key] == nil {
+		u[key] = make(map[string]bool)
+	}
+	u[key][userPkg] = true
+}
+
+// addExternalUsage marks the exports named by keys, in "pkgpath.Name" form, as
+// used by an unknown package.
+func addExternalUsage(keys []string, exports map[string]Export, used usage) {
+	for _, key := range keys {
+		if exp, ok := exports[key]; ok {
+			used.add(exportUsageKey(exp), "")
+		}
+	}
+}
+
 func findExternalUsage(
 	opts Options,
 	res *rta.Result,
 	allPkgs []*packages.Package,
 	targetPaths map[string]bool,
-) map[string]bool {
-	used := make(map[string]bool)
-	findCrossPackageCalls(opts, res, targetPaths, used)
-	findTypeRefsInReachable(opts, res, targetPaths, used)
-	findExternalUsageTypesInfo(opts, allPkgs, targetPaths, used)
+	ignoredFiles map[string]bool,
+	unreachable *unreachableFuncs,
+) usage {
+	used := make(usage)
+	findCrossPackageCalls(opts, res, targetPaths, ignoredFiles, used)
+	findTypeRefsInReachable(opts, res, targetPaths, ignoredFiles, used)
+	findExternalUsageTypesInfo(opts, allPkgs, targetPaths, ignoredFiles, unreachable, used)
+	findConstraintMethodRefs(opts, allPkgs, targetPaths, ignoredFiles, unreachable, used)
 	return used
 }
 
-func findCrossPackageCalls(opts Options, res *rta.Result, targetPaths, used map[string]bool) {
-	for fn, node := range res.CallGraph.Nodes {
-		if fn == nil || fn.Pkg == nil {
-			continue
+// findTestFiles returns the names of all test files in pkgs, including the
+// synthesized _testmain.go files of test executables.
+func findTestFiles(pkgs []*packages.Package) map[string]bool {
+	testFiles := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			name := pkg.Fset.File(file.Pos()).Name()
+			if strings.HasSuffix(name, "_test.go") || filepath.Base(name) == "_testmain.go" {
+				testFiles[name] = true
+			}
 		}
-		callerPkg := normalizePkgPath(fn.Pkg.Pkg.Path(), opts)
+	}
+	return testFiles
+}
 
-		for _, edge := range node.Out {
-			callee := edge.Callee.Func
-			if callee == nil {
-				continue
-			}
-			calleePkg := getSSAPkgPath(callee)
-			if calleePkg == "" || !targetPaths[calleePkg] || callerPkg == calleePkg {
-				continue
-			}
-			key := buildSSAKey(callee)
-			if key != "" {
-				used[key] = true
+// findGeneratedFiles returns the names of all generated files in pkgs.
+func findGeneratedFiles(pkgs []*packages.Package, isGenerated func(string, *ast.File) bool) map[string]bool {
+	generated := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			filename := pkg.Fset.File(file.Pos()).Name()
+			if isGeneratedFile(isGenerated, filename, file) {
+				generated[filename] = true
 			}
 		}
 	}
+	return generated
 }
 
-func findTypeRefsInReachable(opts Options, res *rta.Result, targetPaths, used map[string]bool) {
-	for fn := range res.Reachable {
+// isGeneratedFile reports whether file is generated according to
+// isGenerated, falling back to ast.IsGenerated when it is nil.
+func isGeneratedFile(isGenerated func(string, *ast.File) bool, filename string, file *ast.File) bool {
+	if isGenerated == nil {
+		return ast.IsGenerated(file)
+	}
+	return isGenerated(filename, file)
+}
+
+func findCrossPackageCalls(opts Options, res *rta.Result, targetPaths, ignoredFiles map[string]bool, used usage) {
+	for fn, node := range res.CallGraph.Nodes {
 		if fn == nil {
 			continue
 		}
+		// Instantiations of generic functions, and the closures inside
+		// them, have no package of their own and are attributed to the
+		// package of their origin.
+		callerPkg := getSSAPkgPath(fn)
+		if callerPkg == "" {
+			continue
+		}
+		callerPkg = normalizePkgPath(callerPkg, opts)
+		recordCallees(fn, node, callerPkg, targetPaths, ignoredFiles, used)
+	}
+}
+
+// recordCallees marks the functions of other target packages that fn, in
+// callerPkg, calls outside of ignored files as used by callerPkg.
+func recordCallees(fn *ssa.Function, node *callgraph.Node, callerPkg string, targetPaths, ignoredFiles map[string]bool, used usage) {
+	for _, edge := range node.Out {
+		callee := edge.Callee.Func
+		if callee == nil {
+			continue
+		}
+		calleePkg := getSSAPkgPath(callee)
+		if calleePkg == "" || !targetPaths[calleePkg] || callerPkg == calleePkg {
+			continue
+		}
+		if ignoredFiles[fn.Prog.Fset.Position(edge.Pos()).Filename] {
+			continue
+		}
+		key, ok := buildSSAKey(callee)
+		if ok {
+			used.add(key, callerPkg)
+		}
+	}
+}
+
+func findTypeRefsInReachable(opts Options, res *rta.Result, targetPaths, ignoredFiles map[string]bool, used usage) {
+	for fn := range res.Reachable {
+		if fn == nil || ignoredFiles[fn.Prog.Fset.Position(fn.Pos()).Filename] {
+			continue
+		}
 		callerPkg := getSSAPkgPath(fn)
 		if callerPkg == "" {
 			continue
@@ -353,21 +1479,37 @@ func findTypeRefsInReachable(opts Options, res *rta.Result, targetPaths, used ma
 	}
 }
 
+// normalizePkgPath returns the package path that usage from pkgPath is
+// attributed to. External test packages (foo_test) are treated as the same
+// package as foo unless tests are analyzed as separate packages.
 func normalizePkgPath(pkgPath string, opts Options) string {
-	if !opts.Test {
+	pkgPath = vendorlessPath(pkgPath)
+	if !opts.Test || !opts.ExternalTestAsExternal {
 		return strings.TrimSuffix(pkgPath, "_test")
 	}
 	return pkgPath
 }
 
-// getSSAPkgPath returns the package path for an SSA function.
+// vendorlessPath returns the canonical path of a package vendored in a
+// "vendor" directory below the root of an import path, such as b/c for
+// a/vendor/b/c. Usage of a vendored copy counts toward the canonical
+// package it is a copy of. The standard library's own vendored packages,
+// whose paths start with "vendor/", are left alone.
+func vendorlessPath(pkgPath string) string {
+	if i := strings.LastIndex(pkgPath, "/vendor/"); i >= 0 {
+		return pkgPath[i+len("/vendor/"):]
+	}
+	return pkgPath
+}
+
+// getSSAPkgPath returns the canonical package path for an SSA function.
 // For instantiated generic functions, Pkg is nil but Origin().Pkg is set.
 func getSSAPkgPath(fn *ssa.Function) string {
 	switch {
 	case fn.Pkg != nil:
-		return fn.Pkg.Pkg.Path()
+		return vendorlessPath(fn.Pkg.Pkg.Path())
 	case fn.Origin() != nil && fn.Origin().Pkg != nil:
-		return fn.Origin().Pkg.Pkg.Path()
+		return vendorlessPath(fn.Origin().Pkg.Pkg.Path())
 	default:
 		return ""
 	}
@@ -376,58 +1518,185 @@ func getSSAPkgPath(fn *ssa.Function) string {
 // findExternalUsageTypesInfo finds externally used exports by examining
 // TypesInfo.Uses across all packages. This catches references to consts,
 // vars, types, and functions that RTA's call graph doesn't track.
-func findExternalUsageTypesInfo(opts Options, allPkgs []*packages.Package, targetPaths, used map[string]bool) {
+func findExternalUsageTypesInfo(
+	opts Options,
+	allPkgs []*packages.Package,
+	targetPaths, ignoredFiles map[string]bool,
+	unreachable *unreachableFuncs,
+	used usage,
+) {
 	for _, pkg := range allPkgs {
 		if pkg.TypesInfo == nil {
 			continue
 		}
-		callerPkg := pkg.PkgPath
-		// When not including tests, treat external test packages (foo_test)
-		// as the same package as foo. When including tests, external test
-		// packages are considered separate packages.
-		if !opts.Test {
-			callerPkg = strings.TrimSuffix(callerPkg, "_test")
-		}
+		callerPkg := normalizePkgPath(pkg.PkgPath, opts)
 
-		for _, obj := range pkg.TypesInfo.Uses {
+		for ident, obj := range pkg.TypesInfo.Uses {
 			if obj == nil || obj.Pkg() == nil {
 				continue
 			}
-			objPkg := obj.Pkg().Path()
+			if ignoredFiles[pkg.Fset.Position(ident.Pos()).Filename] {
+				continue
+			}
+			recordTypesInfoUse(obj, ident.Pos(), callerPkg, targetPaths, unreachable, used)
+		}
+	}
+}
 
-			// Only care about references to target packages
-			if !targetPaths[objPkg] {
+// recordTypesInfoUse marks obj as used by callerPkg when it is an export of
+// another target package referenced at pos from reachable code.
+func recordTypesInfoUse(
+	obj types.Object,
+	pos token.Pos,
+	callerPkg string,
+	targetPaths map[string]bool,
+	unreachable *unreachableFuncs,
+	used usage,
+) {
+	objPkg := vendorlessPath(obj.Pkg().Path())
+	// Only care about external references to target packages
+	if !targetPaths[objPkg] || callerPkg == objPkg || !obj.Exported() {
+		return
+	}
+	key, ok := typesInfoUsageKey(obj)
+	if ok && !unreachable.skip(pos, key) {
+		used.add(key, callerPkg)
+	}
+}
+
+// findConstraintMethodRefs marks the methods a target type needs to satisfy
+// the constraint of a generic function or type from another package that it
+// instantiates. The generic code calls them through the type parameter, which
+// neither the call graph nor TypesInfo.Uses attributes to the type.
+func findConstraintMethodRefs(
+	opts Options,
+	allPkgs []*packages.Package,
+	targetPaths, ignoredFiles map[string]bool,
+	unreachable *unreachableFuncs,
+	used usage,
+) {
+	for _, pkg := range allPkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for ident, inst := range pkg.TypesInfo.Instances {
+			if ignoredFiles[pkg.Fset.Position(ident.Pos()).Filename] {
 				continue
 			}
+			obj := pkg.TypesInfo.Uses[ident]
+			if obj == nil || obj.Pkg() == nil {
+				continue
+			}
+			genericPkg := normalizePkgPath(obj.Pkg().Path(), opts)
+			tparams := genericTypeParams(obj)
+			for i := range min(tparams.Len(), inst.TypeArgs.Len()) {
+				iface, ok := tparams.At(i).Constraint().Underlying().(*types.Interface)
+				if ok {
+					recordConstraintMethods(iface, inst.TypeArgs.At(i), genericPkg, ident.Pos(), targetPaths, unreachable, used)
+				}
+			}
+		}
+	}
+}
+
+// genericTypeParams returns the type parameters of a generic function or
+// type, or nil for any other object.
+func genericTypeParams(obj types.Object) *types.TypeParamList {
+	switch obj := obj.(type) {
+	case *types.Func:
+		return obj.Signature().TypeParams()
+	case *types.TypeName:
+		if named, ok := obj.Type().(*types.Named); ok {
+			return named.TypeParams()
+		}
+	}
+	return nil
+}
 
-			// Check if this is an external reference
-			if callerPkg != objPkg && obj.Exported() {
-				key := objPkg + "." + obj.Name()
-				used[key] = true
+// recordConstraintMethods marks the exported methods of constraint iface as
+// used by genericPkg when typeArg is a target type from another package. pos
+// is the position of the instantiation.
+func recordConstraintMethods(
+	iface *types.Interface,
+	typeArg types.Type,
+	genericPkg string,
+	pos token.Pos,
+	targetPaths map[string]bool,
+	unreachable *unreachableFuncs,
+	used usage,
+) {
+	if ptr, isPtr := typeArg.(*types.Pointer); isPtr {
+		typeArg = ptr.Elem()
+	}
+	named, ok := types.Unalias(typeArg).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return
+	}
+	typePkg := vendorlessPath(named.Obj().Pkg().Path())
+	if !targetPaths[typePkg] || typePkg == genericPkg {
+		return
+	}
+	for method := range iface.Methods() {
+		if !method.Exported() {
+			continue
+		}
+		key := usageKey{pkgPath: typePkg, typeName: named.Obj().Name(), name: method.Name()}
+		if !unreachable.skip(pos, key) {
+			used.add(key, genericPkg)
+		}
+	}
+}
+
+// typesInfoUsageKey returns the usageKey for a referenced object. Methods are
+// keyed by their receiver type so they don't collide with package-level
+// identifiers of the same name. References to plain struct fields don't name
+// any export and return false; embedded fields are named after their type and
+// are keyed by name.
+func typesInfoUsageKey(obj types.Object) (usageKey, bool) {
+	pkgPath := vendorlessPath(obj.Pkg().Path())
+	switch obj := obj.(type) {
+	case *types.Func:
+		if recv := obj.Signature().Recv(); recv != nil {
+			typeName := getReceiverTypeName(recv.Type())
+			if typeName == "" {
+				return usageKey{}, false
 			}
+			return usageKey{pkgPath: pkgPath, typeName: typeName, name: obj.Name()}, true
+		}
+	case *types.Var:
+		if obj.IsField() && !obj.Embedded() {
+			return usageKey{}, false
 		}
 	}
+	return usageKey{pkgPath: pkgPath, name: obj.Name()}, true
 }
 
-func buildSSAKey(fn *ssa.Function) string {
+func buildSSAKey(fn *ssa.Function) (usageKey, bool) {
+	// Attribute instantiations of generic functions and methods to their
+	// generic origin, which has the package and the unsubstituted receiver.
+	if fn != nil && fn.Pkg == nil && fn.Origin() != nil {
+		fn = fn.Origin()
+	}
 	if fn == nil || fn.Pkg == nil {
-		return ""
+		return usageKey{}, false
 	}
-	pkgPath := fn.Pkg.Pkg.Path()
+	pkgPath := vendorlessPath(fn.Pkg.Pkg.Path())
 
 	// Check if this is a method
 	recv := fn.Signature.Recv()
 	if recv != nil {
 		typeName := getReceiverTypeName(recv.Type())
 		if typeName != "" {
-			return pkgPath + "." + typeName + "." + fn.Name()
+			return usageKey{pkgPath: pkgPath, typeName: typeName, name: fn.Name()}, true
 		}
 	}
-	return pkgPath + "." + fn.Name()
+	return usageKey{pkgPath: pkgPath, name: fn.Name()}, true
 }
 
 func getReceiverTypeName(t types.Type) string {
-	switch tp := t.(type) {
+	// A method declared with an alias as its receiver, as in
+	// "type A = T; func (A) M()", belongs to the aliased type.
+	switch tp := types.Unalias(t).(type) {
 	case *types.Named:
 		return tp.Obj().Name()
 	case *types.Pointer:
@@ -436,7 +1705,7 @@ func getReceiverTypeName(t types.Type) string {
 	return ""
 }
 
-func collectTypeRefsFromFunc(fn *ssa.Function, callerPkg string, targetPaths, used map[string]bool) {
+func collectTypeRefsFromFunc(fn *ssa.Function, callerPkg string, targetPaths map[string]bool, used usage) {
 	// Check parameter types
 	for _, param := range fn.Params {
 		collectTypeRefs(param.Type(), callerPkg, targetPaths, used)
@@ -460,100 +1729,691 @@ func collectTypeRefsFromFunc(fn *ssa.Function, callerPkg string, targetPaths, us
 				collectTypeRefs(v.X.Type(), callerPkg, targetPaths, used)
 			case *ssa.Field:
 				collectTypeRefs(v.X.Type(), callerPkg, targetPaths, used)
+			case *ssa.MakeInterface:
+				collectInterfaceMethodRefs(v, callerPkg, targetPaths, used)
 			}
 		}
 	}
 }
 
-func collectTypeRefs(t types.Type, callerPkg string, targetPaths, used map[string]bool) {
+// collectInterfaceMethodRefs marks the methods a target type needs to satisfy
+// an interface it is converted to from another package. The methods may never
+// be called, but unexporting them would break the conversion.
+func collectInterfaceMethodRefs(v *ssa.MakeInterface, callerPkg string, targetPaths map[string]bool, used usage) {
+	iface, ok := v.Type().Underlying().(*types.Interface)
+	if !ok {
+		return
+	}
+	t := v.X.Type()
+	if ptr, isPtr := t.(*types.Pointer); isPtr {
+		t = ptr.Elem()
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return
+	}
+	pkgPath := vendorlessPath(named.Obj().Pkg().Path())
+	if !targetPaths[pkgPath] || callerPkg == pkgPath {
+		return
+	}
+	for method := range iface.Methods() {
+		if method.Exported() {
+			used.add(usageKey{pkgPath: pkgPath, typeName: named.Obj().Name(), name: method.Name()}, callerPkg)
+		}
+	}
+}
+
+// collectTypeRefs marks the exported named types and aliases of target
+// packages that make up t. It walks t with an explicit stack rather than
+// recursion so that deeply nested types can't exhaust the goroutine stack.
+// Named types end the walk along their path without visiting their
+// underlying types, which also keeps recursive types from looping.
+func collectTypeRefs(t types.Type, callerPkg string, targetPaths map[string]bool, used usage) {
+	stack := []types.Type{t}
+	for len(stack) > 0 {
+		t := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		switch tp := t.(type) {
+		case *types.Alias:
+			addTypeRef(tp.Obj(), callerPkg, targetPaths, used)
+		case *types.Named:
+			addTypeRef(tp.Obj(), callerPkg, targetPaths, used)
+		}
+		stack = appendTypeComponents(stack, t)
+	}
+}
+
+// addTypeRef marks obj as used by callerPkg if it is an exported type of a
+// target package other than callerPkg.
+func addTypeRef(obj *types.TypeName, callerPkg string, targetPaths map[string]bool, used usage) {
+	if obj == nil || obj.Pkg() == nil {
+		return
+	}
+	pkgPath := vendorlessPath(obj.Pkg().Path())
+	if targetPaths[pkgPath] && callerPkg != pkgPath && token.IsExported(obj.Name()) {
+		used.add(usageKey{pkgPath: pkgPath, name: obj.Name()}, callerPkg)
+	}
+}
+
+// appendTypeComponents appends the types collectTypeRefs visits next from t
+// to stack: the right-hand side of an alias, the type arguments of a named
+// type and the element, parameter, result, field and method types of other
+// types.
+func appendTypeComponents(stack []types.Type, t types.Type) []types.Type {
 	switch tp := t.(type) {
 	case *types.Alias:
-		collectAliasTypeRefs(tp, callerPkg, targetPaths, used)
+		stack = append(stack, tp.Rhs())
 	case *types.Named:
-		collectNamedTypeRefs(tp, callerPkg, targetPaths, used)
+		stack = slices.AppendSeq(stack, tp.TypeArgs().Types())
 	case *types.Pointer, *types.Slice, *types.Array, *types.Chan:
 		type el interface{ Elem() types.Type }
-		collectTypeRefs(tp.(el).Elem(), callerPkg, targetPaths, used)
+		stack = append(stack, tp.(el).Elem())
 	case *types.Map:
-		collectTypeRefs(tp.Key(), callerPkg, targetPaths, used)
-		collectTypeRefs(tp.Elem(), callerPkg, targetPaths, used)
+		stack = append(stack, tp.Key(), tp.Elem())
 	case *types.Signature:
-		collectSignatureTypeRefs(tp, callerPkg, targetPaths, used)
+		stack = appendVarTypes(stack, tp.Params().Variables())
+		stack = appendVarTypes(stack, tp.Results().Variables())
 	case *types.Struct:
-		for field := range tp.Fields() {
-			collectTypeRefs(field.Type(), callerPkg, targetPaths, used)
-		}
+		stack = appendVarTypes(stack, tp.Fields())
 	case *types.Interface:
 		for method := range tp.Methods() {
-			collectTypeRefs(method.Type(), callerPkg, targetPaths, used)
+			stack = append(stack, method.Type())
+		}
+	}
+	return stack
+}
+
+// appendVarTypes appends the types of vars to stack.
+func appendVarTypes(stack []types.Type, vars iter.Seq[*types.Var]) []types.Type {
+	for v := range vars {
+		stack = append(stack, v.Type())
+	}
+	return stack
+}
+
+// resultBuilder sorts the exports of an analyzedProgram into the reported
+// ones and, as the options ask, the inventory and the exports used only by
+// Options.UsedOnlyBy packages.
+type resultBuilder struct {
+	*analyzedProgram
+	keep   map[string]bool
+	result *Result
+}
+
+// buildResult returns the result for the exports that pass the filter,
+// Exclude and PendingDeletion, without the annotations that look at the
+// reported exports together.
+func (a *analyzedProgram) buildResult() *Result {
+	b := &resultBuilder{
+		analyzedProgram: a,
+		keep:            make(map[string]bool, len(a.opts.Keep)),
+		result:          &Result{},
+	}
+	for _, k := range a.opts.Keep {
+		b.keep[k] = true
+	}
+	for key, exp := range a.exports {
+		if a.inScope(exp) {
+			b.add(key, exp)
+		}
+	}
+	if a.opts.CollapseMethods {
+		b.result.Exports = collapseMethods(b.result.Exports)
+	}
+	byName := func(a, b InventoryEntry) int {
+		return cmp.Or(cmp.Compare(a.PkgPath, b.PkgPath), cmp.Compare(a.Name, b.Name))
+	}
+	slices.SortFunc(b.result.Inventory, byName)
+	slices.SortFunc(b.result.UsedOnlyBy, byName)
+	return b.result
+}
+
+// inScope reports whether exp's package passes the filter and matches
+// neither Exclude nor PendingDeletion.
+func (l *loadedProgram) inScope(exp Export) bool {
+	if l.filter != nil && !l.filter.MatchString(exp.PkgPath) {
+		return false
+	}
+	return !matchPackagePatterns(l.opts.Exclude, exp.PkgPath) &&
+		!matchPackagePatterns(l.opts.PendingDeletion, exp.PkgPath)
+}
+
+// add sorts exp, the export with key, into the result.
+func (b *resultBuilder) add(key string, exp Export) {
+	if b.keep[key] {
+		b.record(exp, "suppressed", nil)
+		return
+	}
+	users := b.users(&exp)
+	if len(users) > 0 && !b.flagReportedUse(key, &exp, users) {
+		b.recordUsed(exp, users)
+		return
+	}
+	// Skip generated files unless includeGenerated is true
+	if !b.opts.Generated && b.generated[exp.Position.File] {
+		b.record(exp, "kept-generated", users)
+		return
+	}
+	if b.templateNamed(&exp) {
+		b.record(exp, "used", users)
+		return
+	}
+	exp.MaybeUsedByIgnoredFile = b.ignoredFileRefs[key]
+	exp.UsedByGenerated = hasOtherUsers(b.generatedUsed[exportUsageKey(exp)], users)
+	exp.OnlyUsedByUnreachable = b.unreachable.users(exp)
+	exp.Confidence = exportConfidence(exp)
+	b.result.Exports = append(b.result.Exports, exp)
+	b.record(exp, "over-exported", users)
+}
+
+// users returns the packages that use exp from outside its package, leaving
+// out packages pending deletion. It sets WillBeOrphaned on exp when those
+// were its only users.
+func (b *resultBuilder) users(exp *Export) map[string]bool {
+	users := b.externallyUsed[exportUsageKey(*exp)]
+	if len(b.opts.PendingDeletion) == 0 || len(users) == 0 {
+		return users
+	}
+	users = usersExcept(users, b.opts.PendingDeletion)
+	exp.WillBeOrphaned = len(users) == 0
+	return users
+}
+
+// flagReportedUse sets the flag on exp for usage by users that is reported
+// anyway, such as usage only by examples with Options.ExamplesOnly. It
+// returns false if the usage is real.
+func (b *resultBuilder) flagReportedUse(key string, exp *Export, users map[string]bool) bool {
+	switch {
+	case b.opts.ExamplesOnly && allExamplePackages(users):
+		exp.ExamplesOnly = true
+	case b.mockOnly[key]:
+		exp.MockOnlyInterface = true
+	// nonTestUsed is only populated for StrictTest and InternalStrict.
+	case len(b.nonTestUsed[exportUsageKey(*exp)]) > 0:
+		return false
+	case isInternalPkg(exp.PkgPath):
+		exp.InternalStrict = b.opts.InternalStrict
+		return exp.InternalStrict
+	default:
+		exp.TestCoverageGap = b.opts.StrictTest
+		return exp.TestCoverageGap
+	}
+	return true
+}
+
+// templateNamed reports whether exp is a method named in a template executed
+// with a value of its type. Otherwise it sets MaybeTemplateAccessed on exp
+// when a template may still call it.
+func (b *resultBuilder) templateNamed(exp *Export) bool {
+	typeName, methodName, ok := strings.Cut(exp.Name, ".")
+	if !ok || exp.Kind != "method" {
+		return false
+	}
+	maybeAccessed, named := b.templates.check(exp.PkgPath, typeName, methodName)
+	exp.MaybeTemplateAccessed = maybeAccessed && !named
+	return named
+}
+
+// record adds exp to the inventory with status, if there is one.
+func (b *resultBuilder) record(exp Export, status string, users map[string]bool) {
+	if b.opts.Inventory {
+		b.result.Inventory = append(b.result.Inventory, InventoryEntry{Export: exp, Status: status, UsedBy: knownUsers(users)})
+	}
+}
+
+// recordUsed records exp as used by users, and lists it in UsedOnlyBy when
+// they all match Options.UsedOnlyBy.
+func (b *resultBuilder) recordUsed(exp Export, users map[string]bool) {
+	b.record(exp, "used", users)
+	if len(b.opts.UsedOnlyBy) > 0 && allUsersMatch(users, b.opts.UsedOnlyBy) {
+		b.result.UsedOnlyBy = append(b.result.UsedOnlyBy, InventoryEntry{Export: exp, Status: "used", UsedBy: knownUsers(users)})
+	}
+}
+
+// allUsersMatch reports whether every package in users matches one of
+// patterns. An unknown user never matches.
+func allUsersMatch(users map[string]bool, patterns []string) bool {
+	for user := range users {
+		if user == "" || !matchPackagePatterns(patterns, user) {
+			return false
 		}
 	}
+	return true
 }
 
-func collectAliasTypeRefs(tp *types.Alias, callerPkg string, targetPaths, used map[string]bool) {
-	if tp.Obj() != nil && tp.Obj().Pkg() != nil {
-		pkgPath := tp.Obj().Pkg().Path()
-		if targetPaths[pkgPath] && callerPkg != pkgPath && token.IsExported(tp.Obj().Name()) {
-			used[pkgPath+"."+tp.Obj().Name()] = true
+// hasOtherUsers reports whether any package in users isn't in counted.
+func hasOtherUsers(users, counted map[string]bool) bool {
+	for user := range users {
+		if !counted[user] {
+			return true
 		}
 	}
-	// Also check the underlying type
-	collectTypeRefs(tp.Rhs(), callerPkg, targetPaths, used)
+	return false
 }
 
-func collectNamedTypeRefs(tp *types.Named, callerPkg string, targetPaths, used map[string]bool) {
-	if tp.Obj() != nil && tp.Obj().Pkg() != nil {
-		pkgPath := tp.Obj().Pkg().Path()
-		if targetPaths[pkgPath] && callerPkg != pkgPath && token.IsExported(tp.Obj().Name()) {
-			used[pkgPath+"."+tp.Obj().Name()] = true
+// usersExcept returns the packages in users that don't match any of
+// patterns. An unknown user never matches.
+func usersExcept(users map[string]bool, patterns []string) map[string]bool {
+	remaining := make(map[string]bool, len(users))
+	for user := range users {
+		if user == "" || !matchPackagePatterns(patterns, user) {
+			remaining[user] = true
 		}
 	}
-	ta := tp.TypeArgs()
-	if ta != nil {
-		for tat := range ta.Types() {
-			collectTypeRefs(tat, callerPkg, targetPaths, used)
+	return remaining
+}
+
+// knownUsers returns the sorted paths of the known packages in users.
+func knownUsers(users map[string]bool) []string {
+	var pkgs []string
+	for pkg := range users {
+		if pkg != "" {
+			pkgs = append(pkgs, pkg)
 		}
 	}
+	slices.Sort(pkgs)
+	return pkgs
 }
 
-func collectSignatureTypeRefs(tp *types.Signature, callerPkg string, targetPaths, used map[string]bool) {
-	for v := range tp.Params().Variables() {
-		collectTypeRefs(v.Type(), callerPkg, targetPaths, used)
+// onlyExports returns the exports named by keys. It returns an error naming
+// any key that isn't in exports.
+func onlyExports(exports map[string]Export, keys []string) (map[string]Export, error) {
+	only := make(map[string]Export, len(keys))
+	var missing []string
+	for _, key := range keys {
+		exp, ok := exports[key]
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+		only[key] = exp
 	}
-	for v := range tp.Results().Variables() {
-		collectTypeRefs(v.Type(), callerPkg, targetPaths, used)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("exported identifiers not found: %s", strings.Join(missing, ", "))
 	}
+	return only, nil
 }
 
-func buildResult(
-	opts Options,
-	exports map[string]Export,
-	externallyUsed map[string]bool,
-	generated map[string]bool,
-	filter *regexp.Regexp,
-) *Result {
-	var result []Export
+// collapseMethods removes methods whose receiver type is also in exports.
+func collapseMethods(exports []Export) []Export {
+	reportedTypes := make(map[string]bool)
+	for _, exp := range exports {
+		if exp.Kind == "type" {
+			reportedTypes[exp.PkgPath+"."+exp.Name] = true
+		}
+	}
+	return slices.DeleteFunc(exports, func(exp Export) bool {
+		typeName, _, ok := strings.Cut(exp.Name, ".")
+		return ok && exp.Kind == "method" && reportedTypes[exp.PkgPath+"."+typeName]
+	})
+}
 
-	for key, exp := range exports {
-		if externallyUsed[key] {
+// markPossibleDuplicates sets PossibleDuplicates on reported functions that
+// share an identical signature with other reported functions in the same
+// package.
+func markPossibleDuplicates(exports []Export, allPkgs []*packages.Package) {
+	for _, candidates := range reportedFuncsByPkg(exports, typesPackages(allPkgs)) {
+		for _, c := range candidates {
+			var dups []string
+			for _, other := range candidates {
+				if other.idx != c.idx && types.Identical(c.sig, other.sig) {
+					dups = append(dups, exports[other.idx].Name)
+				}
+			}
+			slices.Sort(dups)
+			exports[c.idx].PossibleDuplicates = dups
+		}
+	}
+}
+
+// reportedFunc is a reported function and its index in the exports.
+type reportedFunc struct {
+	idx int
+	sig *types.Signature
+}
+
+// reportedFuncsByPkg returns the package-level functions in exports grouped
+// by package path.
+func reportedFuncsByPkg(exports []Export, typesPkgs map[string]*types.Package) map[string][]reportedFunc {
+	byPkg := make(map[string][]reportedFunc)
+	for i, exp := range exports {
+		if exp.Kind != "func" || typesPkgs[exp.PkgPath] == nil {
+			continue
+		}
+		fn, ok := typesPkgs[exp.PkgPath].Scope().Lookup(exp.Name).(*types.Func)
+		if !ok {
 			continue
 		}
-		// Skip generated files unless includeGenerated is true
-		if !opts.Generated && generated[exp.Position.File] {
+		byPkg[exp.PkgPath] = append(byPkg[exp.PkgPath], reportedFunc{idx: i, sig: fn.Signature()})
+	}
+	return byPkg
+}
+
+// markTopLevelNameClashes sets SharesTopLevelName on reported methods named
+// like a package-level identifier in the same package.
+func markTopLevelNameClashes(exports []Export, allPkgs []*packages.Package) {
+	scopes := make(map[string]*types.Scope, len(allPkgs))
+	for _, pkg := range allPkgs {
+		if pkg.Types != nil {
+			scopes[pkg.PkgPath] = pkg.Types.Scope()
+		}
+	}
+	for i, exp := range exports {
+		if exp.Kind != "method" || scopes[exp.PkgPath] == nil {
 			continue
 		}
-		// Apply filter
-		if filter != nil && !filter.MatchString(exp.PkgPath) {
+		methodName := exp.Name[strings.LastIndex(exp.Name, ".")+1:]
+		exports[i].SharesTopLevelName = scopes[exp.PkgPath].Lookup(methodName) != nil
+	}
+}
+
+// markNoExternalImplementers sets NoExternalImplementers on reported
+// interfaces that no package-level type declared in another package
+// implements, by value or by pointer.
+func markNoExternalImplementers(exports []Export, allPkgs []*packages.Package) {
+	typesPkgs := typesPackages(allPkgs)
+	ifaces := reportedInterfaces(exports, typesPkgs)
+	// Checking every type against every interface is costly, so only
+	// collect the candidates when there is an interface to check.
+	if len(ifaces) == 0 {
+		return
+	}
+	candidates := implementerCandidates(typesPkgs)
+	for i, iface := range ifaces {
+		pkgPath := exports[i].PkgPath
+		implemented := slices.ContainsFunc(candidates, func(c *types.TypeName) bool {
+			return c.Pkg().Path() != pkgPath &&
+				(types.Implements(c.Type(), iface) || types.Implements(types.NewPointer(c.Type()), iface))
+		})
+		exports[i].NoExternalImplementers = !implemented
+	}
+}
+
+// reportedInterfaces returns the non-generic interfaces in exports keyed by
+// their index.
+func reportedInterfaces(exports []Export, typesPkgs map[string]*types.Package) map[int]*types.Interface {
+	ifaces := make(map[int]*types.Interface)
+	for i, exp := range exports {
+		if exp.Kind != "type" || typesPkgs[exp.PkgPath] == nil {
 			continue
 		}
-		// Apply exclude
-		if len(opts.Exclude) > 0 && matchPackagePatterns(opts.Exclude, exp.PkgPath) {
+		tn, ok := typesPkgs[exp.PkgPath].Scope().Lookup(exp.Name).(*types.TypeName)
+		if !ok {
 			continue
 		}
-		result = append(result, exp)
+		named, ok := tn.Type().(*types.Named)
+		if !ok || named.TypeParams().Len() > 0 {
+			continue
+		}
+		if iface, ok := named.Underlying().(*types.Interface); ok {
+			ifaces[i] = iface
+		}
 	}
+	return ifaces
+}
 
-	return &Result{Exports: result}
+// implementerCandidates returns the package-level types that could implement
+// an interface: those that are not aliases, generic or interfaces themselves.
+func implementerCandidates(typesPkgs map[string]*types.Package) []*types.TypeName {
+	var candidates []*types.TypeName
+	for _, pkg := range typesPkgs {
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok || named.TypeParams().Len() > 0 || types.IsInterface(named) {
+				continue
+			}
+			candidates = append(candidates, tn)
+		}
+	}
+	return candidates
+}
+
+// markEnumGroups sets EnumGroup on reported constants of a named type that
+// has at least two constants in its package.
+func markEnumGroups(exports []Export, allPkgs []*packages.Package) {
+	scopes := make(map[string]*types.Scope, len(allPkgs))
+	for _, pkg := range allPkgs {
+		if pkg.Types != nil {
+			scopes[pkg.PkgPath] = pkg.Types.Scope()
+		}
+	}
+	constCounts := constTypeCounts(scopes)
+	for i, exp := range exports {
+		if exp.Kind != "const" || scopes[exp.PkgPath] == nil {
+			continue
+		}
+		tn := constTypeName(scopes[exp.PkgPath].Lookup(exp.Name))
+		if tn != nil && constCounts[tn] > 1 {
+			exports[i].EnumGroup = tn.Name()
+		}
+	}
+}
+
+// constTypeCounts returns the number of package-level constants of each
+// named type declared in scopes.
+func constTypeCounts(scopes map[string]*types.Scope) map[*types.TypeName]int {
+	constCounts := make(map[*types.TypeName]int)
+	for _, scope := range scopes {
+		for _, name := range scope.Names() {
+			if tn := constTypeName(scope.Lookup(name)); tn != nil {
+				constCounts[tn]++
+			}
+		}
+	}
+	return constCounts
+}
+
+// markDeadParamTypes sets DeadParamTypes on reported functions and methods
+// whose parameters are of reported types.
+func markDeadParamTypes(exports []Export, allPkgs []*packages.Package) {
+	reportedTypes := make(map[string]bool)
+	for _, exp := range exports {
+		if exp.Kind == "type" {
+			reportedTypes[exp.PkgPath+"."+exp.Name] = true
+		}
+	}
+	if len(reportedTypes) == 0 {
+		return
+	}
+	typesPkgs := typesPackages(allPkgs)
+	for i, exp := range exports {
+		if exp.Kind != "func" && exp.Kind != "method" {
+			continue
+		}
+		fn := lookupFunc(typesPkgs[exp.PkgPath], exp.Name)
+		if fn != nil {
+			exports[i].DeadParamTypes = deadParamTypes(fn, reportedTypes)
+		}
+	}
+}
+
+// typesPackages returns the type-checked packages in allPkgs keyed by path.
+func typesPackages(allPkgs []*packages.Package) map[string]*types.Package {
+	typesPkgs := make(map[string]*types.Package, len(allPkgs))
+	for _, pkg := range allPkgs {
+		if pkg.Types != nil {
+			typesPkgs[pkg.PkgPath] = pkg.Types
+		}
+	}
+	return typesPkgs
+}
+
+// deadParamTypes returns the sorted "pkgpath.Name" of the types in
+// reportedTypes that fn has parameters of.
+func deadParamTypes(fn *types.Func, reportedTypes map[string]bool) []string {
+	var dead []string
+	for param := range fn.Signature().Params().Variables() {
+		named := paramNamedType(param.Type())
+		if named == nil || named.Obj().Pkg() == nil {
+			continue
+		}
+		key := named.Obj().Pkg().Path() + "." + named.Obj().Name()
+		if reportedTypes[key] {
+			dead = append(dead, key)
+		}
+	}
+	slices.Sort(dead)
+	return slices.Compact(dead)
+}
+
+// lookupFunc returns the function or method of pkg named name, in "Name" or
+// "Type.Method" form, or nil if there is none.
+func lookupFunc(pkg *types.Package, name string) *types.Func {
+	if pkg == nil {
+		return nil
+	}
+	typeName, methodName, isMethod := strings.Cut(name, ".")
+	if !isMethod {
+		fn, _ := pkg.Scope().Lookup(name).(*types.Func)
+		return fn
+	}
+	tn, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	obj, _, _ := types.LookupFieldOrMethod(tn.Type(), true, pkg, methodName)
+	fn, _ := obj.(*types.Func)
+	return fn
+}
+
+// paramNamedType returns the named type of a parameter of type t, looking
+// through pointers, slices, arrays, maps and channels, or nil if there is
+// none. For maps, the element type is used.
+func paramNamedType(t types.Type) *types.Named {
+	for {
+		switch tp := types.Unalias(t).(type) {
+		case *types.Named:
+			return tp
+		case *types.Pointer:
+			t = tp.Elem()
+		case *types.Slice:
+			t = tp.Elem()
+		case *types.Array:
+			t = tp.Elem()
+		case *types.Map:
+			t = tp.Elem()
+		case *types.Chan:
+			t = tp.Elem()
+		default:
+			return nil
+		}
+	}
+}
+
+// markNameHints sets NameHint on exports whose name matches pattern. Methods
+// are matched by their own name, without the receiver type.
+func markNameHints(exports []Export, pattern *regexp.Regexp) {
+	if pattern == nil {
+		return
+	}
+	for i, exp := range exports {
+		name := exp.Name[strings.LastIndex(exp.Name, ".")+1:]
+		exports[i].NameHint = pattern.MatchString(name)
+	}
+}
+
+// constTypeName returns the named type of obj if obj is a constant of a named
+// type declared in the same package.
+func constTypeName(obj types.Object) *types.TypeName {
+	c, ok := obj.(*types.Const)
+	if !ok {
+		return nil
+	}
+	named, ok := c.Type().(*types.Named)
+	if !ok || named.Obj().Pkg() != c.Pkg() {
+		return nil
+	}
+	return named.Obj()
+}
+
+// isInternalPkg reports whether pkgPath has an "internal" path element.
+func isInternalPkg(pkgPath string) bool {
+	return slices.Contains(strings.Split(pkgPath, "/"), "internal")
+}
+
+// allExamplePackages reports whether every package in pkgPaths has an
+// "example" or "examples" path element.
+func allExamplePackages(pkgPaths map[string]bool) bool {
+	for pkgPath := range pkgPaths {
+		isExample := slices.ContainsFunc(strings.Split(pkgPath, "/"), func(elem string) bool {
+			return elem == "example" || elem == "examples"
+		})
+		if !isExample {
+			return false
+		}
+	}
+	return true
+}
+
+// isDynamicMethodName reports whether name is a method name commonly
+// satisfied through dynamic interface checks (type assertions in fmt,
+// encoding/json, database/sql and the like) that the analysis may not see.
+func isDynamicMethodName(name string) bool {
+	switch name {
+	case "As", "Error", "Format", "GoString", "Is",
+		"MarshalBinary", "MarshalJSON", "MarshalText", "MarshalYAML",
+		"Scan", "ServeHTTP", "String", "Unwrap", "Value",
+		"UnmarshalBinary", "UnmarshalJSON", "UnmarshalText", "UnmarshalYAML":
+		return true
+	}
+	return false
+}
+
+// exportConfidence scores how safe it is to unexport exp, from a base score
+// for its kind scaled down by each risk signal it carries. See
+// Export.Confidence for the values.
+func exportConfidence(exp Export) float64 {
+	methodName := exp.Name[strings.LastIndex(exp.Name, ".")+1:]
+	score := kindConfidence(exp)
+	for _, risk := range []struct {
+		present bool
+		factor  float64
+	}{
+		{exp.Kind == "method" && isDynamicMethodName(methodName), 0.5},
+		{exp.MaybeTemplateAccessed, 0.5},
+		{exp.MaybeUsedByIgnoredFile, 0.5},
+		{exp.UsedByGenerated, 0.5},
+		{len(exp.OnlyUsedByUnreachable) > 0, 0.8},
+		{hasRemainingUsers(exp), 0.7},
+	} {
+		if risk.present {
+			score *= risk.factor
+		}
+	}
+	return math.Round(score*100) / 100
+}
+
+// hasRemainingUsers reports whether exp is reported despite users that
+// would have to change, such as examples or tests.
+func hasRemainingUsers(exp Export) bool {
+	return exp.ExamplesOnly || exp.MockOnlyInterface || exp.WillBeOrphaned ||
+		exp.TestCoverageGap || exp.InternalStrict
+}
+
+// kindConfidence returns the base confidence score for the kind of exp.
+// Functions and constants can only be reached by name. Variables can also be
+// set by linker flags, types can be reached through reflection and methods
+// may satisfy an interface that is only checked at run time, which is less
+// likely when the receiver type is unexported.
+func kindConfidence(exp Export) float64 {
+	switch exp.Kind {
+	case "var":
+		return 0.9
+	case "type":
+		return 0.8
+	case "method":
+		if exp.UnreachableReceiver {
+			return 0.9
+		}
+		return 0.6
+	default:
+		return 1
+	}
 }
 
 // buildFilterPattern builds a regexp from the filter flag value.
@@ -0,0 +1,62 @@
+package overexported
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/callgraph/rta"
+	"golang.org/x/tools/go/packages"
+)
+
+// addDebugReasons sets Reasons on exports by checking each usage source on its
+// own. This repeats the usage analysis, so it is only done on request.
+func addDebugReasons(
+	opts Options,
+	exports []Export,
+	res *rta.Result,
+	allPkgs []*packages.Package,
+	targetPaths map[string]bool,
+	ignoredFiles map[string]bool,
+	unreachable *unreachableFuncs,
+) {
+	calls := make(usage)
+	findCrossPackageCalls(opts, res, targetPaths, ignoredFiles, calls)
+	typeRefs := make(usage)
+	findTypeRefsInReachable(opts, res, targetPaths, ignoredFiles, typeRefs)
+	refs := make(usage)
+	findExternalUsageTypesInfo(opts, allPkgs, targetPaths, ignoredFiles, unreachable, refs)
+	runtimeTypes := make(usage)
+	markRuntimeTypes(res, targetPaths, runtimeTypes)
+
+	for i, exp := range exports {
+		key := exportUsageKey(exp)
+		reasons := []string{
+			usageReason(calls[key], "no external call", "external call"),
+			usageReason(typeRefs[key], "no type ref", "type ref"),
+			usageReason(refs[key], "no external reference", "external reference"),
+		}
+		if exp.Kind == "type" {
+			reasons = append(reasons, usageReason(runtimeTypes[key], "not in runtime types", "in runtime types"))
+		}
+		exports[i].Reasons = reasons
+	}
+}
+
+// usageReason describes the users found by one usage source, naming the
+// using packages when they are known.
+func usageReason(users map[string]bool, none, found string) string {
+	if len(users) == 0 {
+		return none
+	}
+	var pkgs []string
+	for pkg := range users {
+		if pkg != "" {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	if len(pkgs) == 0 {
+		return found
+	}
+	slices.Sort(pkgs)
+	return found + " from " + strings.Join(pkgs, ", ")
+}
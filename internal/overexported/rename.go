@@ -0,0 +1,141 @@
+package overexported
+
+import (
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// initialisms returns the word prefixes lowercased as a unit, in the casing
+// they are usually written in. They are matched at the start of a name,
+// followed by the end of the name, a digit, an uppercase letter or a plural
+// "s". A longer leading run of uppercase letters still wins, so IDE isn't
+// split after ID.
+func initialisms() []string {
+	return []string{
+		"ACL", "API", "ASCII", "CPU", "CSS", "CSV", "DNS", "EOF", "GUID", "HTML",
+		"HTTP", "HTTPS", "ID", "IO", "IP", "IPv4", "IPv6", "JSON", "JWT", "LHS",
+		"OAuth", "OK", "QPS", "RAM", "RHS", "RPC", "SLA", "SMTP", "SQL", "SSH",
+		"TCP", "TLS", "TTL", "UDP", "UI", "UID", "URI", "URL", "UTF8", "UUID",
+		"VM", "XML", "XMPP", "XSRF", "XSS",
+	}
+}
+
+// markSuggestedNames sets SuggestedName on exports. No name is suggested
+// when it would collide with a name already in scope: for methods, a field or
+// method of the receiver type; otherwise a predeclared identifier such as new
+// or string, or a name its package already declares.
+func markSuggestedNames(exports []Export, allPkgs []*packages.Package) {
+	typesPkgs := typesPackages(allPkgs)
+	for i, exp := range exports {
+		name := unexportedName(exp.Name[strings.LastIndex(exp.Name, ".")+1:])
+		if name != "" && !nameInScope(exp, name, typesPkgs[exp.PkgPath]) {
+			exports[i].SuggestedName = name
+		}
+	}
+}
+
+// nameInScope reports whether renaming exp to name would collide with a name
+// already in scope. A method's name only has to be distinct among the fields
+// and methods of its receiver type; it can't shadow a predeclared identifier.
+func nameInScope(exp Export, name string, pkg *types.Package) bool {
+	if pkg == nil {
+		return types.Universe.Lookup(name) != nil
+	}
+	if exp.Kind != "method" {
+		return types.Universe.Lookup(name) != nil || pkg.Scope().Lookup(name) != nil
+	}
+	typeName, _, _ := strings.Cut(exp.Name, ".")
+	tn, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(tn.Type(), true, pkg, name)
+	return obj != nil
+}
+
+// unexportedName returns an unexported form of the exported name, keeping
+// initialisms in one case: HTTPClient becomes httpClient, URL becomes url and
+// OAuth2Token becomes oauth2Token. It returns "" when the result would be a
+// Go keyword, such as for Type.
+func unexportedName(name string) string {
+	n := leadingWordLen(name)
+	unexported := strings.ToLower(name[:n]) + name[n:]
+	if token.IsKeyword(unexported) {
+		return ""
+	}
+	return unexported
+}
+
+// leadingWordLen returns the length in bytes of the prefix of name that is
+// lowercased to unexport it: the longest initialism it starts with, or its
+// leading uppercase word if that is longer.
+func leadingWordLen(name string) int {
+	return max(initialismLen(name), upperWordLen(name))
+}
+
+// initialismLen returns the length in bytes of the longest initialism name
+// starts with as a whole word, or 0 if there is none.
+func initialismLen(name string) int {
+	best := 0
+	for _, initialism := range initialisms() {
+		if len(initialism) > best && strings.HasPrefix(name, initialism) && isWordBoundary(name, len(initialism)) {
+			best = len(initialism)
+		}
+	}
+	return best
+}
+
+// upperWordLen returns the length in bytes of the leading run of uppercase
+// letters in name, less the last one when it starts a lowercase word, as in
+// FOOBar.
+func upperWordLen(name string) int {
+	var runes []int
+	for i, r := range name {
+		if !unicode.IsUpper(r) {
+			break
+		}
+		runes = append(runes, i)
+	}
+	switch {
+	case len(runes) == 0:
+		return 0
+	case len(runes) == 1:
+		_, size := utf8.DecodeRuneInString(name)
+		return size
+	}
+	last := runes[len(runes)-1]
+	_, size := utf8.DecodeRuneInString(name[last:])
+	end := last + size
+	if end < len(name) {
+		r, _ := utf8.DecodeRuneInString(name[end:])
+		if unicode.IsLower(r) {
+			return last
+		}
+	}
+	return end
+}
+
+// isWordBoundary reports whether name[i:] starts a new word after an
+// initialism: it is empty, or starts with a digit, an uppercase letter or a
+// plural "s" that is itself followed by a boundary.
+func isWordBoundary(name string, i int) bool {
+	if i == len(name) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(name[i:])
+	if r == 's' && (i+1 == len(name) || startsWord(name[i+1:])) {
+		return true
+	}
+	return startsWord(name[i:])
+}
+
+// startsWord reports whether s starts with an uppercase letter or a digit.
+func startsWord(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsUpper(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,124 @@
+package overexported
+
+import (
+	"go/constant"
+	"go/types"
+	"regexp"
+
+	"golang.org/x/tools/go/callgraph/rta"
+	"golang.org/x/tools/go/ssa"
+)
+
+// templateAccess records what text/template and html/template may reach at
+// run time, where the analysis can't follow.
+type templateAccess struct {
+	// types holds keys of target types whose values are passed to a template
+	// execution.
+	types map[string]bool
+	// names holds identifiers referenced as ".Name" in template strings
+	// passed to Parse.
+	names map[string]bool
+	// fieldPattern matches ".Name" references in template sources.
+	fieldPattern *regexp.Regexp
+}
+
+// findTemplateAccess looks for template executions and template sources in
+// reachable code. Any value passed as template data is treated as able to
+// reach the exported methods of its type, and of the types of its fields,
+// elements and so on.
+func findTemplateAccess(res *rta.Result, targetPaths map[string]bool) *templateAccess {
+	ta := &templateAccess{
+		types: make(map[string]bool),
+		names: make(map[string]bool),
+
+		fieldPattern: regexp.MustCompile(`\.([A-Za-z_][A-Za-z0-9_]*)`),
+	}
+	for fn := range res.Reachable {
+		for _, block := range fn.Blocks {
+			for _, instr := range block.Instrs {
+				call, ok := instr.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				ta.inspectCall(call.Common(), targetPaths)
+			}
+		}
+	}
+	return ta
+}
+
+func (ta *templateAccess) inspectCall(common *ssa.CallCommon, targetPaths map[string]bool) {
+	callee := common.StaticCallee()
+	if callee == nil || callee.Pkg == nil || len(common.Args) == 0 {
+		return
+	}
+	switch callee.Pkg.Pkg.Path() {
+	case "text/template", "html/template":
+	default:
+		return
+	}
+	last := common.Args[len(common.Args)-1]
+	switch callee.Name() {
+	case "Execute", "ExecuteTemplate":
+		ta.addData(last, targetPaths)
+	case "Parse":
+		ta.addSourceNames(last)
+	}
+}
+
+// addData records the type of data, the value passed to a template
+// execution.
+func (ta *templateAccess) addData(data ssa.Value, targetPaths map[string]bool) {
+	if mi, ok := data.(*ssa.MakeInterface); ok {
+		ta.addType(mi.X.Type(), targetPaths, make(map[types.Type]bool))
+	}
+}
+
+// addSourceNames records the ".Name" references in src, the source passed to
+// Parse, when it is a constant.
+func (ta *templateAccess) addSourceNames(src ssa.Value) {
+	c, ok := src.(*ssa.Const)
+	if !ok || c.Value == nil || c.Value.Kind() != constant.String {
+		return
+	}
+	for _, m := range ta.fieldPattern.FindAllStringSubmatch(constant.StringVal(c.Value), -1) {
+		ta.names[m[1]] = true
+	}
+}
+
+// addType records t and every type reachable from it through pointers,
+// containers and struct fields.
+func (ta *templateAccess) addType(t types.Type, targetPaths map[string]bool, seen map[types.Type]bool) {
+	if seen[t] {
+		return
+	}
+	seen[t] = true
+	switch tp := types.Unalias(t).(type) {
+	case *types.Named:
+		if tp.Obj().Pkg() != nil && targetPaths[tp.Obj().Pkg().Path()] {
+			ta.types[tp.Obj().Pkg().Path()+"."+tp.Obj().Name()] = true
+		}
+		ta.addType(tp.Underlying(), targetPaths, seen)
+	case *types.Pointer, *types.Slice, *types.Array, *types.Chan:
+		type el interface{ Elem() types.Type }
+		ta.addType(tp.(el).Elem(), targetPaths, seen)
+	case *types.Map:
+		ta.addType(tp.Key(), targetPaths, seen)
+		ta.addType(tp.Elem(), targetPaths, seen)
+	case *types.Struct:
+		for field := range tp.Fields() {
+			if field.Exported() {
+				ta.addType(field.Type(), targetPaths, seen)
+			}
+		}
+	}
+}
+
+// check reports whether a template may reach the method typeName.methodName
+// in pkgPath, and whether a parsed template names it.
+func (ta *templateAccess) check(pkgPath, typeName, methodName string) (maybeAccessed, named bool) {
+	if ta == nil || !ta.types[pkgPath+"."+typeName] {
+		return false, false
+	}
+	return true, ta.names[methodName]
+}
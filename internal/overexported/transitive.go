@@ -0,0 +1,129 @@
+package overexported
+
+import (
+	"cmp"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"maps"
+	"slices"
+
+	"golang.org/x/tools/go/callgraph/rta"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+)
+
+// unreachableFuncs records the source ranges of functions and methods that
+// RTA found unreachable. References from their bodies don't count as usage,
+// since deleting the dead code would remove them.
+type unreachableFuncs struct {
+	// ranges is sorted by start and doesn't overlap.
+	ranges []funcRange
+	// refs maps exports to the unreachable functions referencing them.
+	refs map[usageKey]map[string]bool
+}
+
+type funcRange struct {
+	start, end token.Pos
+	// name is the function's key, in "pkgpath.Name" or "pkgpath.Type.Method"
+	// form.
+	name string
+}
+
+// findUnreachableFuncs returns the declared functions and methods in pkgs
+// that aren't reachable in res.
+func findUnreachableFuncs(prog *ssa.Program, res *rta.Result, pkgs []*packages.Package) *unreachableFuncs {
+	reachable := reachableOrigins(res)
+	u := &unreachableFuncs{refs: make(map[usageKey]map[string]bool)}
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				if r, ok := unreachableRange(prog, pkg, decl, reachable); ok {
+					u.ranges = append(u.ranges, r)
+				}
+			}
+		}
+	}
+	slices.SortFunc(u.ranges, func(a, b funcRange) int {
+		return cmp.Compare(a.start, b.start)
+	})
+	return u
+}
+
+// reachableOrigins returns the functions reachable in res along with the
+// generic origins of reachable instantiations. Generic functions are only
+// reachable through their instantiations.
+func reachableOrigins(res *rta.Result) map[*ssa.Function]bool {
+	reachable := make(map[*ssa.Function]bool, len(res.Reachable))
+	for fn := range res.Reachable {
+		reachable[fn] = true
+		if fn.Origin() != nil {
+			reachable[fn.Origin()] = true
+		}
+	}
+	return reachable
+}
+
+// unreachableRange returns the source range of decl if it declares a
+// function or method with a body that isn't in reachable.
+func unreachableRange(prog *ssa.Program, pkg *packages.Package, decl ast.Decl, reachable map[*ssa.Function]bool) (funcRange, bool) {
+	funcDecl, ok := decl.(*ast.FuncDecl)
+	if !ok || funcDecl.Body == nil {
+		return funcRange{}, false
+	}
+	obj, ok := pkg.TypesInfo.Defs[funcDecl.Name].(*types.Func)
+	if !ok {
+		return funcRange{}, false
+	}
+	fn := prog.FuncValue(obj)
+	if fn == nil || reachable[fn] {
+		return funcRange{}, false
+	}
+	name := funcDecl.Name.Name
+	if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
+		name = receiverBaseName(funcDecl.Recv.List[0].Type) + "." + name
+	}
+	return funcRange{
+		start: funcDecl.Pos(),
+		end:   funcDecl.End(),
+		name:  pkg.PkgPath + "." + name,
+	}, true
+}
+
+// skip reports whether a reference to key at pos is inside an unreachable
+// function, recording the function if so. It is safe to call on a nil
+// *unreachableFuncs.
+func (u *unreachableFuncs) skip(pos token.Pos, key usageKey) bool {
+	if u == nil {
+		return false
+	}
+	i, found := slices.BinarySearchFunc(u.ranges, pos, func(r funcRange, pos token.Pos) int {
+		return cmp.Compare(r.start, pos)
+	})
+	if !found {
+		i--
+	}
+	if i < 0 || pos >= u.ranges[i].end {
+		return false
+	}
+	if u.refs[key] == nil {
+		u.refs[key] = make(map[string]bool)
+	}
+	u.refs[key][u.ranges[i].name] = true
+	return true
+}
+
+// users returns the sorted keys of the unreachable functions referencing exp.
+func (u *unreachableFuncs) users(exp Export) []string {
+	if u == nil {
+		return nil
+	}
+	refs := u.refs[exportUsageKey(exp)]
+	if len(refs) == 0 {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(refs))
+}
@@ -0,0 +1,122 @@
+package overexported
+
+import (
+	"go/ast"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// removeDocumented removes exports whose declaration has a doc comment.
+func removeDocumented(exports []Export, allPkgs []*packages.Package, targetPaths map[string]bool) []Export {
+	docs := findDocComments(allPkgs, targetPaths)
+	return slices.DeleteFunc(exports, func(exp Export) bool {
+		return docs[exp.PkgPath+"."+exp.Name] != nil
+	})
+}
+
+// removeDeprecated removes exports whose doc comment has a paragraph
+// starting with "Deprecated: ".
+func removeDeprecated(exports []Export, allPkgs []*packages.Package, targetPaths map[string]bool) []Export {
+	docs := findDocComments(allPkgs, targetPaths)
+	return slices.DeleteFunc(exports, func(exp Export) bool {
+		doc := docs[exp.PkgPath+"."+exp.Name]
+		if doc == nil {
+			return false
+		}
+		for paragraph := range strings.SplitSeq(doc.Text(), "\n\n") {
+			if strings.HasPrefix(paragraph, "Deprecated: ") {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// findDocComments returns the doc comments of declarations in the target
+// packages, keyed by "pkgpath.Name" or "pkgpath.Type.Method".
+func findDocComments(allPkgs []*packages.Package, targetPaths map[string]bool) map[string]*ast.CommentGroup {
+	docs := make(map[string]*ast.CommentGroup)
+	for _, pkg := range allPkgs {
+		if !targetPaths[pkg.PkgPath] {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			collectDocComments(pkg.PkgPath, file, docs)
+		}
+	}
+	return docs
+}
+
+// collectDocComments adds the doc comments of declarations in file to docs.
+// A comment on a parenthesized group doesn't document its members.
+func collectDocComments(pkgPath string, file *ast.File, docs map[string]*ast.CommentGroup) {
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			collectFuncDoc(pkgPath, decl, docs)
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				collectSpecDoc(pkgPath, decl, spec, docs)
+			}
+		}
+	}
+}
+
+// collectFuncDoc adds the doc comment of a function or method declaration
+// to docs.
+func collectFuncDoc(pkgPath string, decl *ast.FuncDecl, docs map[string]*ast.CommentGroup) {
+	if decl.Doc == nil {
+		return
+	}
+	name := decl.Name.Name
+	if decl.Recv != nil && len(decl.Recv.List) > 0 {
+		name = receiverBaseName(decl.Recv.List[0].Type) + "." + name
+	}
+	docs[pkgPath+"."+name] = decl.Doc
+}
+
+// collectSpecDoc adds the doc comment of a type or value spec in decl to
+// docs.
+func collectSpecDoc(pkgPath string, decl *ast.GenDecl, spec ast.Spec, docs map[string]*ast.CommentGroup) {
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		if doc := specDoc(decl, spec.Doc); doc != nil {
+			docs[pkgPath+"."+spec.Name.Name] = doc
+		}
+	case *ast.ValueSpec:
+		if doc := specDoc(decl, spec.Doc); doc != nil {
+			for _, name := range spec.Names {
+				docs[pkgPath+"."+name.Name] = doc
+			}
+		}
+	}
+}
+
+// specDoc returns the doc comment of a spec in decl: its own, or the
+// declaration's when decl isn't a parenthesized group.
+func specDoc(decl *ast.GenDecl, doc *ast.CommentGroup) *ast.CommentGroup {
+	if doc == nil && !decl.Lparen.IsValid() {
+		return decl.Doc
+	}
+	return doc
+}
+
+// receiverBaseName returns the type name of a method receiver expression,
+// without any pointer or type parameters.
+func receiverBaseName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverBaseName(expr.X)
+	case *ast.ParenExpr:
+		return receiverBaseName(expr.X)
+	case *ast.IndexExpr:
+		return receiverBaseName(expr.X)
+	case *ast.IndexListExpr:
+		return receiverBaseName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package overexported
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"golang.org/x/tools/go/callgraph/rta"
+	"golang.org/x/tools/go/ssa"
+)
+
+// WriteOnlyVar is an exported package-level variable that other packages
+// assign to but never read. These are often better served by an unexported
+// variable and a setter.
+type WriteOnlyVar struct {
+	Name     string   `json:"name"`
+	PkgPath  string   `json:"package"`
+	Position Position `json:"position"`
+	// Writers lists the packages that assign to the variable.
+	Writers []string `json:"writers"`
+}
+
+// findWriteOnlyVars returns the exported variables in target packages that
+// reachable code in other packages stores to but never loads, sorted by
+// package and name. Any use of a variable other than a direct store, such as
+// taking its address or assigning to one of its fields, counts as a read.
+func findWriteOnlyVars(opts Options, res *rta.Result, targetPaths map[string]bool) []WriteOnlyVar {
+	acc := varAccesses{
+		writers: make(map[*ssa.Global]map[string]bool),
+		read:    make(map[*ssa.Global]bool),
+	}
+	var operands []*ssa.Value
+	for fn := range res.Reachable {
+		callerPkg := getSSAPkgPath(fn)
+		if callerPkg == "" {
+			continue
+		}
+		callerPkg = normalizePkgPath(callerPkg, opts)
+		for _, block := range fn.Blocks {
+			for _, instr := range block.Instrs {
+				operands = instr.Operands(operands[:0])
+				acc.record(instr, operands, callerPkg, targetPaths)
+			}
+		}
+	}
+	return acc.writeOnly()
+}
+
+// varAccesses records how code outside their packages uses the exported
+// variables of target packages.
+type varAccesses struct {
+	// writers maps stored-to variables to the packages storing to them.
+	writers map[*ssa.Global]map[string]bool
+	read    map[*ssa.Global]bool
+}
+
+// record records the accesses instr, in callerPkg, makes to the variables
+// of other target packages among its operands.
+func (va *varAccesses) record(instr ssa.Instruction, operands []*ssa.Value, callerPkg string, targetPaths map[string]bool) {
+	for i, op := range operands {
+		g := externalTargetGlobal(*op, callerPkg, targetPaths)
+		if g == nil {
+			continue
+		}
+		// The address operand of a store is its first operand.
+		if _, isStore := instr.(*ssa.Store); isStore && i == 0 {
+			if va.writers[g] == nil {
+				va.writers[g] = make(map[string]bool)
+			}
+			va.writers[g][callerPkg] = true
+			continue
+		}
+		va.read[g] = true
+	}
+}
+
+// externalTargetGlobal returns v if it is an exported variable of a target
+// package other than callerPkg, and nil otherwise.
+func externalTargetGlobal(v ssa.Value, callerPkg string, targetPaths map[string]bool) *ssa.Global {
+	g, ok := v.(*ssa.Global)
+	if !ok || g.Pkg == nil || g.Object() == nil || !g.Object().Exported() {
+		return nil
+	}
+	pkgPath := g.Pkg.Pkg.Path()
+	if !targetPaths[pkgPath] || pkgPath == callerPkg {
+		return nil
+	}
+	return g
+}
+
+// writeOnly returns the recorded variables that were stored to but never
+// read, sorted by package and name.
+func (va *varAccesses) writeOnly() []WriteOnlyVar {
+	var result []WriteOnlyVar
+	for g, pkgs := range va.writers {
+		if va.read[g] {
+			continue
+		}
+		posn := g.Pkg.Prog.Fset.Position(g.Pos())
+		result = append(result, WriteOnlyVar{
+			Name:     g.Name(),
+			PkgPath:  g.Pkg.Pkg.Path(),
+			Position: Position{File: posn.Filename, Line: posn.Line, Col: posn.Column},
+			Writers:  slices.Sorted(maps.Keys(pkgs)),
+		})
+	}
+	slices.SortFunc(result, func(a, b WriteOnlyVar) int {
+		return cmp.Or(cmp.Compare(a.PkgPath, b.PkgPath), cmp.Compare(a.Name, b.Name))
+	})
+	return result
+}